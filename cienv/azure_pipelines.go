@@ -0,0 +1,55 @@
+package cienv
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// IsInAzurePipelines returns true if reviewdog is running in Azure Pipelines.
+func IsInAzurePipelines() bool {
+	// https://learn.microsoft.com/en-us/azure/devops/pipelines/build/variables
+	return os.Getenv("TF_BUILD") == "True"
+}
+
+// GetAzureDevOpsBuildInfo returns Azure DevOps specific build info from
+// predefined variables of Azure Pipelines. Owner is the project name.
+//
+// Document: https://learn.microsoft.com/en-us/azure/devops/pipelines/build/variables
+func GetAzureDevOpsBuildInfo() (*BuildInfo, error) {
+	collectionURI := os.Getenv("SYSTEM_COLLECTIONURI")
+	if collectionURI == "" {
+		return nil, errors.New("cannot get collection URI from environment variable. Set SYSTEM_COLLECTIONURI ?")
+	}
+	project := os.Getenv("SYSTEM_TEAMPROJECT")
+	if project == "" {
+		return nil, errors.New("cannot get project from environment variable. Set SYSTEM_TEAMPROJECT ?")
+	}
+	repo := os.Getenv("BUILD_REPOSITORY_NAME")
+	if repo == "" {
+		return nil, errors.New("cannot get repository name from environment variable. Set BUILD_REPOSITORY_NAME ?")
+	}
+	// BUILD_SOURCEVERSION is the merge commit in pull request builds.
+	sha := getOneEnvValue([]string{
+		"SYSTEM_PULLREQUEST_SOURCECOMMITID",
+		"BUILD_SOURCEVERSION",
+	})
+	if sha == "" {
+		return nil, errors.New("cannot get commit SHA from environment variable. Set BUILD_SOURCEVERSION ?")
+	}
+	branch := getOneEnvValue([]string{
+		"SYSTEM_PULLREQUEST_SOURCEBRANCH",
+		"BUILD_SOURCEBRANCH",
+	})
+	pr, _ := strconv.Atoi(os.Getenv("SYSTEM_PULLREQUEST_PULLREQUESTID"))
+
+	return &BuildInfo{
+		Owner:                    project,
+		Repo:                     repo,
+		SHA:                      sha,
+		PullRequest:              pr,
+		Branch:                   strings.TrimPrefix(branch, "refs/heads/"),
+		AzureDevOpsCollectionURI: collectionURI,
+	}, nil
+}
@@ -3,6 +3,7 @@ package cienv
 
 import (
 	"errors"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
@@ -24,6 +25,12 @@ type BuildInfo struct {
 	// Gerrit related params
 	GerritChangeID   string
 	GerritRevisionID string
+	GerritAddress    string
+	// GerritSource describes where the Gerrit params come from.
+	GerritSource string
+
+	// Azure DevOps related params. Owner is the project name.
+	AzureDevOpsCollectionURI string
 }
 
 // GetBuildInfo returns BuildInfo from environment variables.
@@ -35,7 +42,7 @@ type BuildInfo struct {
 // - GitLab CI: https://docs.gitlab.com/ee/ci/variables/#predefined-variables-environment-variables
 // - GitLab CI doesn't export ID of Merge Request. https://gitlab.com/gitlab-org/gitlab-ce/issues/15280
 func GetBuildInfo() (prInfo *BuildInfo, isPR bool, err error) {
-	if IsInGitHubAction() {
+	if IsInGitHubAction() || IsInGiteaActions() {
 		return getBuildInfoFromGitHubAction()
 	}
 	owner, repo := getOwnerAndRepoFromSlug([]string{
@@ -102,8 +109,25 @@ func GetBuildInfo() (prInfo *BuildInfo, isPR bool, err error) {
 	}, pr != 0, nil
 }
 
-// GetGerritBuildInfo returns Gerrit specific build info
+// GetGerritBuildInfo returns Gerrit specific build info. It detects change
+// info from variables set by Jenkins Gerrit Trigger plugin, or from Zuul
+// variables (ZUUL_* variables exported by jobs or the Zuul inventory file in
+// ZUUL_INVENTORY) if GERRIT_CHANGE_ID and GERRIT_REVISION_ID are not set.
+//
+// Supported CI services' documents:
+// - Gerrit Trigger: https://plugins.jenkins.io/gerrit-trigger/
+// - Zuul: https://zuul-ci.org/docs/zuul/latest/job-content.html#variables
 func GetGerritBuildInfo() (*BuildInfo, error) {
+	switch {
+	case os.Getenv("GERRIT_CHANGE_ID") != "" && os.Getenv("GERRIT_REVISION_ID") != "":
+	case os.Getenv("GERRIT_CHANGE_NUMBER") != "" && os.Getenv("GERRIT_PATCHSET_REVISION") != "":
+		return getGerritBuildInfoFromGerritTrigger()
+	case os.Getenv("ZUUL_CHANGE") != "" && os.Getenv("ZUUL_PATCHSET") != "":
+		return getGerritBuildInfoFromZuulEnv()
+	case os.Getenv("ZUUL_INVENTORY") != "":
+		return getGerritBuildInfoFromZuulInventory(os.Getenv("ZUUL_INVENTORY"))
+	}
+
 	changeID := os.Getenv("GERRIT_CHANGE_ID")
 	if changeID == "" {
 		return nil, errors.New("cannot get change id from environment variable. Set GERRIT_CHANGE_ID ?")
@@ -122,10 +146,63 @@ func GetGerritBuildInfo() (*BuildInfo, error) {
 	return &BuildInfo{
 		GerritChangeID:   changeID,
 		GerritRevisionID: revisionID,
+		GerritAddress:    os.Getenv("GERRIT_ADDRESS"),
+		GerritSource:     "GERRIT_CHANGE_ID and GERRIT_REVISION_ID",
 		Branch:           branch,
 	}, nil
 }
 
+func getGerritBuildInfoFromGerritTrigger() (*BuildInfo, error) {
+	branch := os.Getenv("GERRIT_BRANCH")
+	if branch == "" {
+		return nil, errors.New("cannot get branch from Gerrit Trigger environment variable. Set GERRIT_BRANCH ?")
+	}
+	address := os.Getenv("GERRIT_ADDRESS")
+	if address == "" {
+		address = gerritAddressFromChangeURL(os.Getenv("GERRIT_CHANGE_URL"))
+	}
+	if host := os.Getenv("GERRIT_HOST"); address == "" && host != "" {
+		// GERRIT_SCHEME is usually "ssh", which cannot be used for REST API.
+		scheme := os.Getenv("GERRIT_SCHEME")
+		if scheme != "http" && scheme != "https" {
+			scheme = "https"
+		}
+		address = scheme + "://" + host
+	}
+	return &BuildInfo{
+		GerritChangeID:   gerritChangeID(os.Getenv("GERRIT_PROJECT"), os.Getenv("GERRIT_CHANGE_NUMBER")),
+		GerritRevisionID: os.Getenv("GERRIT_PATCHSET_REVISION"),
+		GerritAddress:    address,
+		GerritSource:     "Jenkins Gerrit Trigger",
+		Branch:           branch,
+	}, nil
+}
+
+// gerritChangeID returns a change ID in "<project>~<change number>" format.
+func gerritChangeID(project, number string) string {
+	if project == "" {
+		return number
+	}
+	return url.PathEscape(project) + "~" + number
+}
+
+// gerritAddressFromChangeURL returns the address of Gerrit from the URL of a
+// change (e.g. https://review.example.com/c/project/+/123 or
+// https://review.example.com/123).
+func gerritAddressFromChangeURL(changeURL string) string {
+	u, err := url.Parse(changeURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	p := strings.TrimSuffix(u.Path, "/")
+	if i := strings.Index(p, "/c/"); i >= 0 {
+		p = p[:i]
+	} else if i := strings.LastIndex(p, "/"); i >= 0 {
+		p = p[:i]
+	}
+	return u.Scheme + "://" + u.Host + p
+}
+
 func getPullRequestNum() int {
 	envs := []string{
 		// Common.
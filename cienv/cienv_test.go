@@ -37,6 +37,33 @@ func setupEnvs() (cleanup func()) {
 		"GERRIT_CHANGE_ID",
 		"GERRIT_REVISION_ID",
 		"GERRIT_BRANCH",
+		"GERRIT_ADDRESS",
+		"GERRIT_CHANGE_NUMBER",
+		"GERRIT_PATCHSET_REVISION",
+		"GERRIT_PROJECT",
+		"GERRIT_CHANGE_URL",
+		"GERRIT_HOST",
+		"GERRIT_SCHEME",
+		"ZUUL_CHANGE",
+		"ZUUL_PATCHSET",
+		"ZUUL_PROJECT",
+		"ZUUL_BRANCH",
+		"ZUUL_CHANGE_URL",
+		"ZUUL_INVENTORY",
+		"SYSTEM_COLLECTIONURI",
+		"SYSTEM_TEAMPROJECT",
+		"BUILD_REPOSITORY_NAME",
+		"BUILD_SOURCEVERSION",
+		"BUILD_SOURCEBRANCH",
+		"SYSTEM_PULLREQUEST_PULLREQUESTID",
+		"SYSTEM_PULLREQUEST_SOURCECOMMITID",
+		"SYSTEM_PULLREQUEST_SOURCEBRANCH",
+		"GITEA_ACTIONS",
+		"FORGEJO_ACTIONS",
+		"GITEA_SERVER_URL",
+		"FORGEJO_SERVER_URL",
+		"GITHUB_SERVER_URL",
+		"GITHUB_EVENT_PATH",
 	}
 	saveEnvs := make(map[string]string)
 	for _, key := range cleanEnvs {
@@ -300,3 +327,182 @@ func TestGetGerritBuildInfo(t *testing.T) {
 		t.Error("nil expected but got err")
 	}
 }
+
+func TestGetGerritBuildInfo_gerritTrigger(t *testing.T) {
+	cleanup := setupEnvs()
+	defer cleanup()
+
+	os.Setenv("GERRIT_CHANGE_ID", "I1293efab014de2")
+	os.Setenv("GERRIT_CHANGE_NUMBER", "123")
+	os.Setenv("GERRIT_PATCHSET_REVISION", "ed318bf9a3c")
+	os.Setenv("GERRIT_PROJECT", "my/project")
+	os.Setenv("GERRIT_HOST", "review.example.com")
+	os.Setenv("GERRIT_SCHEME", "ssh")
+	if _, err := GetGerritBuildInfo(); err == nil {
+		t.Error("error expected but got nil")
+	} else {
+		t.Log(err)
+	}
+
+	os.Setenv("GERRIT_BRANCH", "master")
+	got, err := GetGerritBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{
+		GerritChangeID:   "my%2Fproject~123",
+		GerritRevisionID: "ed318bf9a3c",
+		GerritAddress:    "https://review.example.com",
+		GerritSource:     "Jenkins Gerrit Trigger",
+		Branch:           "master",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	os.Setenv("GERRIT_CHANGE_URL", "http://example.com/gerrit/c/my/project/+/123")
+	if got, _ := GetGerritBuildInfo(); got.GerritAddress != "http://example.com/gerrit" {
+		t.Errorf("got address %q from GERRIT_CHANGE_URL", got.GerritAddress)
+	}
+
+	os.Setenv("GERRIT_ADDRESS", "http://localhost:8080")
+	if got, _ := GetGerritBuildInfo(); got.GerritAddress != "http://localhost:8080" {
+		t.Errorf("got address %q, want GERRIT_ADDRESS", got.GerritAddress)
+	}
+}
+
+func TestGetGerritBuildInfo_zuul(t *testing.T) {
+	cleanup := setupEnvs()
+	defer cleanup()
+
+	os.Setenv("ZUUL_CHANGE", "123")
+	os.Setenv("ZUUL_PATCHSET", "2")
+	os.Setenv("ZUUL_PROJECT", "project")
+	os.Setenv("ZUUL_CHANGE_URL", "https://review.example.com/123")
+	if _, err := GetGerritBuildInfo(); err == nil {
+		t.Error("error expected but got nil")
+	} else {
+		t.Log(err)
+	}
+
+	os.Setenv("ZUUL_BRANCH", "main")
+	got, err := GetGerritBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{
+		GerritChangeID:   "project~123",
+		GerritRevisionID: "2",
+		GerritAddress:    "https://review.example.com",
+		GerritSource:     "Zuul",
+		Branch:           "main",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestGetGerritBuildInfo_zuulInventory(t *testing.T) {
+	cleanup := setupEnvs()
+	defer cleanup()
+
+	os.Setenv("ZUUL_INVENTORY", "_testdata/not_found.yaml")
+	if _, err := GetGerritBuildInfo(); err == nil {
+		t.Error("error expected but got nil")
+	} else {
+		t.Log(err)
+	}
+
+	os.Setenv("ZUUL_INVENTORY", "_testdata/zuul_inventory.yaml")
+	got, err := GetGerritBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{
+		GerritChangeID:   "org%2Fproject~123",
+		GerritRevisionID: "2",
+		GerritAddress:    "https://review.example.com",
+		GerritSource:     "Zuul inventory _testdata/zuul_inventory.yaml",
+		Branch:           "main",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	// A vars file with the zuul variable at the top level.
+	os.Setenv("ZUUL_INVENTORY", "_testdata/zuul_vars.yaml")
+	got, err = GetGerritBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.GerritSource = "Zuul inventory _testdata/zuul_vars.yaml"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestGetAzureDevOpsBuildInfo(t *testing.T) {
+	cleanup := setupEnvs()
+	defer cleanup()
+
+	os.Setenv("SYSTEM_COLLECTIONURI", "https://dev.azure.com/org/")
+	os.Setenv("SYSTEM_TEAMPROJECT", "project")
+	os.Setenv("BUILD_REPOSITORY_NAME", "repo")
+	if _, err := GetAzureDevOpsBuildInfo(); err == nil {
+		t.Error("error expected but got nil")
+	} else {
+		t.Log(err)
+	}
+
+	os.Setenv("BUILD_SOURCEVERSION", "merge-sha")
+	os.Setenv("BUILD_SOURCEBRANCH", "refs/pull/14/merge")
+	os.Setenv("SYSTEM_PULLREQUEST_PULLREQUESTID", "14")
+	os.Setenv("SYSTEM_PULLREQUEST_SOURCECOMMITID", "source-sha")
+	os.Setenv("SYSTEM_PULLREQUEST_SOURCEBRANCH", "refs/heads/feature")
+	got, err := GetAzureDevOpsBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BuildInfo{
+		Owner:                    "project",
+		Repo:                     "repo",
+		SHA:                      "source-sha",
+		PullRequest:              14,
+		Branch:                   "feature",
+		AzureDevOpsCollectionURI: "https://dev.azure.com/org/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestGetBuildInfo_giteaActions(t *testing.T) {
+	cleanup := setupEnvs()
+	defer cleanup()
+
+	os.Setenv("GITEA_ACTIONS", "true")
+	os.Setenv("GITHUB_SERVER_URL", "https://gitea.example.com")
+	os.Setenv("GITHUB_EVENT_PATH", "_testdata/github_event_pull_request.json")
+
+	got, isPR, err := GetBuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isPR {
+		t.Error("isPR should be true")
+	}
+	want := &BuildInfo{Owner: "reviewdog", Repo: "reviewdog", SHA: "cb23119096646023c05e14ea708b7f20cee906d5", PullRequest: 285, Branch: "go1.13"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+	if got, want := GetGiteaServerURL(), "https://gitea.example.com"; got != want {
+		t.Errorf("GetGiteaServerURL() = %q, want %q", got, want)
+	}
+
+	os.Setenv("GITEA_ACTIONS", "")
+	os.Setenv("FORGEJO_ACTIONS", "true")
+	os.Setenv("FORGEJO_SERVER_URL", "https://forgejo.example.com")
+	if got, want := GetGiteaServerURL(), "https://forgejo.example.com"; got != want {
+		t.Errorf("GetGiteaServerURL() = %q, want %q", got, want)
+	}
+}
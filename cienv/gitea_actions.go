@@ -0,0 +1,24 @@
+package cienv
+
+import "os"
+
+// IsInGiteaActions returns true if reviewdog is running in Gitea Actions or
+// Forgejo Actions. They set GitHub Actions compatible variables (GITHUB_*)
+// as well, so GetBuildInfo reads the event payload like GitHub Actions.
+func IsInGiteaActions() bool {
+	return os.Getenv("GITEA_ACTIONS") == "true" || os.Getenv("FORGEJO_ACTIONS") == "true"
+}
+
+// GetGiteaServerURL returns the URL of Gitea (or Forgejo) instance running
+// the workflow, e.g. https://gitea.example.com. It returns empty string
+// outside of Gitea Actions and Forgejo Actions.
+func GetGiteaServerURL() string {
+	if !IsInGiteaActions() {
+		return ""
+	}
+	return getOneEnvValue([]string{
+		"GITEA_SERVER_URL",
+		"FORGEJO_SERVER_URL",
+		"GITHUB_SERVER_URL",
+	})
+}
@@ -0,0 +1,91 @@
+package cienv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// zuulVars is the zuul variable of Zuul jobs.
+// https://zuul-ci.org/docs/zuul/latest/job-content.html#zuul-variables
+type zuulVars struct {
+	Branch    string `yaml:"branch"`
+	Change    string `yaml:"change"`
+	ChangeURL string `yaml:"change_url"`
+	Patchset  string `yaml:"patchset"`
+	Project   struct {
+		Name string `yaml:"name"`
+	} `yaml:"project"`
+}
+
+// zuulInventory is an Ansible inventory written by Zuul, which has the zuul
+// variable in all.vars. A vars file which has the zuul variable at the top
+// level is accepted as well.
+type zuulInventory struct {
+	Zuul *zuulVars `yaml:"zuul"`
+	All  struct {
+		Vars struct {
+			Zuul *zuulVars `yaml:"zuul"`
+		} `yaml:"vars"`
+	} `yaml:"all"`
+}
+
+// getGerritBuildInfoFromZuulEnv returns build info from variables of Zuul.
+// Zuul doesn't export its variables as environment variables, so jobs need to
+// export zuul.change, zuul.patchset, zuul.project.name, zuul.branch and
+// zuul.change_url as ZUUL_CHANGE, ZUUL_PATCHSET, ZUUL_PROJECT, ZUUL_BRANCH
+// and ZUUL_CHANGE_URL like legacy Zuul jobs.
+func getGerritBuildInfoFromZuulEnv() (*BuildInfo, error) {
+	vars := &zuulVars{
+		Branch:    os.Getenv("ZUUL_BRANCH"),
+		Change:    os.Getenv("ZUUL_CHANGE"),
+		ChangeURL: os.Getenv("ZUUL_CHANGE_URL"),
+		Patchset:  os.Getenv("ZUUL_PATCHSET"),
+	}
+	vars.Project.Name = os.Getenv("ZUUL_PROJECT")
+	if vars.Branch == "" {
+		return nil, errors.New("cannot get branch from Zuul environment variable. Set ZUUL_BRANCH ?")
+	}
+	return gerritBuildInfoFromZuul(vars, "Zuul"), nil
+}
+
+// getGerritBuildInfoFromZuulInventory returns build info from the zuul
+// variable in the inventory file written by Zuul.
+func getGerritBuildInfoFromZuulInventory(path string) (*BuildInfo, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Zuul inventory (ZUUL_INVENTORY): %w", err)
+	}
+	var inv zuulInventory
+	if err := yaml.Unmarshal(b, &inv); err != nil {
+		return nil, fmt.Errorf("cannot parse Zuul inventory %s: %w", path, err)
+	}
+	vars := inv.All.Vars.Zuul
+	if vars == nil {
+		vars = inv.Zuul
+	}
+	if vars == nil || vars.Change == "" || vars.Patchset == "" {
+		return nil, fmt.Errorf("Zuul inventory %s doesn't have zuul.change and zuul.patchset. Is it a change pipeline?", path)
+	}
+	if vars.Branch == "" {
+		return nil, fmt.Errorf("Zuul inventory %s doesn't have zuul.branch", path)
+	}
+	return gerritBuildInfoFromZuul(vars, "Zuul inventory "+path), nil
+}
+
+func gerritBuildInfoFromZuul(vars *zuulVars, source string) *BuildInfo {
+	address := os.Getenv("GERRIT_ADDRESS")
+	if address == "" {
+		address = gerritAddressFromChangeURL(vars.ChangeURL)
+	}
+	return &BuildInfo{
+		GerritChangeID: gerritChangeID(vars.Project.Name, vars.Change),
+		// Gerrit accepts patch set numbers as revision IDs.
+		GerritRevisionID: vars.Patchset,
+		GerritAddress:    address,
+		GerritSource:     source,
+		Branch:           vars.Branch,
+	}
+}
@@ -45,6 +45,11 @@ func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isP
 	if err != nil {
 		return err
 	}
+	if cienv.IsInGitHubAction() {
+		if err := writeStepSummary(ctx, filteredResultSet); err != nil {
+			log.Printf("reviewdog: failed to write job summary: %v", err)
+		}
+	}
 	if foundResultShouldReport := reportResults(w, filteredResultSet); foundResultShouldReport {
 		return errors.New("found at least one result in diff")
 	}
@@ -52,6 +57,15 @@ func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isP
 }
 
 func newDoghouseCli(ctx context.Context) (client.DogHouseClientInterface, error) {
+	// Talk to GitHub directly as a GitHub App installation if the App
+	// credentials are provided. It doesn't need the doghouse server.
+	if useGitHubApp() {
+		ghcli, err := githubAppClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &client.GitHubClient{Client: ghcli}, nil
+	}
 	// If skipDoghouseServer is true, run doghouse code directly instead of talking to
 	// the doghouse server because provided GitHub API Token has Check API scope.
 	// You can force skipping the doghouse server if you are generating your own application API token.
@@ -248,3 +262,31 @@ github-pr-check reporter as a fallback.
 	}
 	return shouldFail
 }
+
+// writeStepSummary writes results which are reported via logging command to
+// the job summary as well, because annotations created by logging command are
+// limited to a few per step.
+func writeStepSummary(ctx context.Context, filteredResultSet *reviewdog.FilteredResultMap) error {
+	if filteredResultSet.Len() == 0 {
+		return nil
+	}
+	ss := stepSummaryWriter()
+	if ss == nil {
+		return nil
+	}
+	var err error
+	filteredResultSet.Range(func(name string, results *reviewdog.FilteredResult) {
+		for _, result := range results.FilteredDiagnostic {
+			if !result.ShouldReport {
+				continue
+			}
+			if e := ss.Post(ctx, &reviewdog.Comment{Result: result, ToolName: name}); e != nil && err == nil {
+				err = e
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return ss.Flush(ctx)
+}
@@ -3,6 +3,10 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -56,6 +60,46 @@ func TestNewDoghouseCli_returnGitHubClient(t *testing.T) {
 	}
 }
 
+func TestNewDoghouseCli_returnGitHubClientWithGitHubApp(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "private-key.pem")
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := ioutil.WriteFile(keyPath, pemKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cleanup := setupEnvs(map[string]string{
+		"REVIEWDOG_TOKEN":                       "",
+		"GITHUB_ACTIONS":                        "",
+		"REVIEWDOG_GITHUB_API_TOKEN":            "",
+		"REVIEWDOG_GITHUB_APP_ID":               "14",
+		"REVIEWDOG_GITHUB_APP_INSTALLATION_ID":  "1414",
+		"REVIEWDOG_GITHUB_APP_PRIVATE_KEY_PATH": keyPath,
+	})
+	defer cleanup()
+	cli, err := newDoghouseCli(context.Background())
+	if err != nil {
+		t.Fatalf("failed to create new client: %v", err)
+	}
+	if _, ok := cli.(*client.GitHubClient); !ok {
+		t.Errorf("got %T client, want *client.GitHubClient client", cli)
+	}
+}
+
+func TestNewDoghouseCli_returnErrorForGitHubApp(t *testing.T) {
+	cleanup := setupEnvs(map[string]string{
+		"REVIEWDOG_GITHUB_APP_ID":               "14",
+		"REVIEWDOG_GITHUB_APP_INSTALLATION_ID":  "1414",
+		"REVIEWDOG_GITHUB_APP_PRIVATE_KEY_PATH": "", // missing
+	})
+	defer cleanup()
+	if _, err := newDoghouseCli(context.Background()); err == nil {
+		t.Error("got no error but want REVIEWDOG_GITHUB_APP_PRIVATE_KEY_PATH missing error")
+	}
+}
+
 func TestNewDoghouseCli_returnErrorForGitHubClient(t *testing.T) {
 	cleanup := setupEnvs(map[string]string{
 		"REVIEWDOG_TOKEN":            "",
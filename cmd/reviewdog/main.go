@@ -14,12 +14,14 @@ import (
 	"os"
 	"os/exec"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
 	"golang.org/x/build/gerrit"
 	"golang.org/x/oauth2"
 
+	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v39/github"
 	"github.com/mattn/go-shellwords"
 	"github.com/reviewdog/errorformat/fmts"
@@ -31,11 +33,17 @@ import (
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/parser"
 	"github.com/reviewdog/reviewdog/project"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	azuredevopsservice "github.com/reviewdog/reviewdog/service/azuredevops"
 	bbservice "github.com/reviewdog/reviewdog/service/bitbucket"
 	gerritservice "github.com/reviewdog/reviewdog/service/gerrit"
+	giteaservice "github.com/reviewdog/reviewdog/service/gitea"
 	githubservice "github.com/reviewdog/reviewdog/service/github"
 	"github.com/reviewdog/reviewdog/service/github/githubutils"
 	gitlabservice "github.com/reviewdog/reviewdog/service/gitlab"
+	htmlservice "github.com/reviewdog/reviewdog/service/html"
+	pluginservice "github.com/reviewdog/reviewdog/service/plugin"
+	webhookservice "github.com/reviewdog/reviewdog/service/webhook"
 )
 
 const usageMessage = "" +
@@ -59,15 +67,25 @@ type option struct {
 	level            string
 	guessPullRequest bool
 	tee              bool
+	format           string
 	filterMode       filter.Mode
 	failOnError      bool
+	targetURL        string
+	output           string
+
+	gerritLabel          string
+	gerritLabelApprove   int
+	gerritLabelReject    int
+	gerritLabelThreshold string
+	gerritNotify         string
+	gerritSkipUnresolved bool
 }
 
 const (
 	diffCmdDoc    = `diff command (e.g. "git diff") for local reporter. Do not use --relative flag for git command.`
 	diffStripDoc  = "strip NUM leading components from diff file names (equivalent to 'patch -p') (default is 1 for git diff)"
 	efmsDoc       = `list of supported machine-readable format and errorformat (https://github.com/reviewdog/errorformat)`
-	fDoc          = `format name (run -list to see supported format name) for input. It's also used as tool name in review comment if -name is empty`
+	fDoc          = `format name (run -list to see supported format name) for input, or plugin:<name> to parse input by reviewdog-parser-<name> in $PATH. It's also used as tool name in review comment if -name is empty`
 	fDiffStripDoc = `option for -f=diff: strip NUM leading components from diff file names (equivalent to 'patch -p') (default is 1 for git diff)`
 	listDoc       = `list supported pre-defined format names which can be used as -f arg`
 	nameDoc       = `tool name in review comment. -f is used as tool name if -name is empty`
@@ -88,7 +106,16 @@ const (
 		"nofilter"
 			Do not filter any results.
 `
-	reporterDoc = `reporter of reviewdog results. (local, github-check, github-pr-check, github-pr-review, gitlab-mr-discussion, gitlab-mr-commit)
+	formatDoc = `output format of results to stdout. [unified, pretty].
+		"unified"
+			<file>:<lnum>:<col>: [<tool name>] <message>
+			Default for project config. Input lines are output as is for -f and -efm by default.
+		"pretty"
+			Compiler-style output grouped by file with source lines of results,
+			underlines of columns and suggestions as diff. Colored by severity
+			if stdout is a terminal and NO_COLOR is not set.
+`
+	reporterDoc = `reporter of reviewdog results. (local, github-check, github-pr-check, github-pr-review, github-commit-status, github-actions-summary, gitlab-mr-discussion, gitlab-mr-commit, gitlab-code-quality)
 	"local" (default)
 		Report results to stdout.
 
@@ -97,7 +124,7 @@ const (
 		For Pull Request, you can see report results in GitHub PullRequest Check
 		tab and can control filtering mode by -filter-mode flag.
 
-		There are two options to use this reporter.
+		There are three options to use this reporter.
 
 		Option 1) Run reviewdog from GitHub Actions w/ secrets.GITHUB_TOKEN
 			Note that it reports result to GitHub Actions log console for Pull
@@ -115,6 +142,15 @@ const (
 
 			Note: Token is not required if you run reviewdog in Travis CI.
 
+		Option 3) Use your own GitHub App (e.g. run reviewdog in Jenkins)
+			reviewdog creates check runs directly as the GitHub App installation
+			without talking to the reviewdog server. It exchanges a JWT signed
+			with the App private key for an installation token by itself.
+			$ export REVIEWDOG_GITHUB_APP_ID=14
+			$ export REVIEWDOG_GITHUB_APP_PRIVATE_KEY_PATH=/path/to/private-key.pem
+			# Optional. reviewdog finds the installation of the repository if empty.
+			$ export REVIEWDOG_GITHUB_APP_INSTALLATION_ID=1414
+
 	"github-pr-check"
 		Same as github-check reporter but it only supports Pull Requests.
 
@@ -127,6 +163,28 @@ const (
 		For GitHub Enterprise:
 			$ export GITHUB_API="https://example.githubenterprise.com/api/v3"
 
+	"github-commit-status"
+		Report results as GitHub commit statuses named "reviewdog/<tool name>".
+		It works both for Pull Requests and commits, and it's useful when
+		GitHub Checks API is not available (e.g. GitHub Enterprise without
+		GitHub Apps). The status is "failure" if there are errors in results
+		(severity or -level), otherwise "success".
+
+		1. Set REVIEWDOG_GITHUB_API_TOKEN environment variable (repo:status scope).
+		2. Optionally set -target-url to link statuses to a build or a report.
+
+	"github-actions-summary"
+		Report results to the job summary of GitHub Actions ($GITHUB_STEP_SUMMARY)
+		with a collapsible section per tool. It's not limited by the number of
+		annotations, so it works well for Pull Requests from forked repository.
+		Results over the size limit of job summary (1MiB) are omitted.
+
+		Set REVIEWDOG_GITHUB_API_TOKEN with secrets.GITHUB_TOKEN to filter
+		results by Pull Request diff. Read permission is enough.
+
+		Note that github-check, github-pr-check, and github-pr-review reporters
+		also write the job summary when they fall back to logging command.
+
 	"gitlab-mr-discussion"
 		Report results to GitLab MergeRequest discussion.
 
@@ -137,22 +195,58 @@ const (
 		Alternatively, GITLAB_API can also be defined, and it will take precedence over the former:
 			$ export GITLAB_API="https://example.gitlab.com/api/v4"
 
+		reviewdog resolves its discussions whose results are no longer reported.
+		Set REVIEWDOG_GITLAB_REOPEN_DISCUSSIONS=true to reopen resolved ones
+		when the results are reported again.
+
+		Set REVIEWDOG_GITLAB_COMMIT_STATUS=true to report commit statuses as
+		gitlab-commit-status reporter as well.
+
+	"gitlab-commit-status"
+		Report results as GitLab commit statuses named "reviewdog/<tool name>",
+		which are shown as external jobs of the pipeline in MergeRequest widgets.
+		It works both for MergeRequests and commits. The status is "failed" if
+		there are errors in results (severity or -level), otherwise "success".
+
+		1. Set REVIEWDOG_GITLAB_API_TOKEN environment variable (api scope).
+		2. Optionally set -target-url to link statuses to a build or a report.
+		CI_JOB_URL is used by default.
+
 	"gitlab-mr-commit"
 		Same as gitlab-mr-discussion, but report results to GitLab comments for
-		each commits in Merge Requests.
+		each commits in Merge Requests. Results are reported to the commit which
+		last touched the line. Set REVIEWDOG_GITLAB_MR_COMMIT_HEAD_ONLY=true to
+		report all results to the head commit.
+
+	"gitlab-code-quality"
+		Write results to GitLab Code Quality report (gl-code-quality-report.json)
+		which can be uploaded as artifacts:reports:codequality. API token is not
+		required. Set REVIEWDOG_GITLAB_CODE_QUALITY_REPORT to change the path.
+
+		It reports all results by default. To filter results by diff, set
+		-filter-mode and -diff, or run it in merge request pipelines which
+		compares with CI_MERGE_REQUEST_DIFF_BASE_SHA.
 
 	"gerrit-change-review"
 		Report results to Gerrit Change comments.
 
 		1. Set GERRIT_USERNAME and GERRIT_PASSWORD for basic authentication or
 		GIT_GITCOOKIE_PATH for git cookie based authentication.
-		2. Set GERRIT_CHANGE_ID, GERRIT_REVISION_ID GERRIT_BRANCH abd GERRIT_ADDRESS
+		2. Set GERRIT_CHANGE_ID, GERRIT_REVISION_ID GERRIT_BRANCH abd GERRIT_ADDRESS.
+		They are detected automatically in Jenkins Gerrit Trigger. In Zuul,
+		set ZUUL_INVENTORY to a YAML file with the zuul variable (or a Zuul
+		inventory), or export ZUUL_CHANGE, ZUUL_PATCHSET, ZUUL_PROJECT,
+		ZUUL_BRANCH and ZUUL_CHANGE_URL, as Zuul doesn't export them.
 
 		For example:
 			$ export GERRIT_CHANGE_ID=myproject~master~I1293efab014de2
 			$ export GERRIT_REVISION_ID=ed318bf9a3c
 			$ export GERRIT_BRANCH=master
 			$ export GERRIT_ADDRESS=http://localhost:8080
+
+		3. Optionally set -gerrit-label to vote based on results, e.g.
+		-gerrit-label=Verified votes +1 when there are no errors, otherwise -1.
+		Set -gerrit-notify=OWNER to notify only the change owner.
 	
 	"bitbucket-code-report"
 		Create Bitbucket Code Report via Code Insights
@@ -168,6 +262,77 @@ const (
 		
 		To post results to Bitbucket Server specify BITBUCKET_SERVER_URL.
 
+		It uses -filter-mode=nofilter by default. Other filter modes filter
+		results by Pull Request diff, which needs BITBUCKET_PR_ID and the
+		credentials even in Bitbucket Pipelines.
+
+		Reports are BUG reports with CODE_SMELL annotations by default. Set
+		REVIEWDOG_BITBUCKET_REPORT_TYPES (BUG, SECURITY, COVERAGE or TEST) and
+		REVIEWDOG_BITBUCKET_ANNOTATION_TYPES (CODE_SMELL, VULNERABILITY or BUG)
+		to map runners to the types, e.g. "gosec=SECURITY,golint=BUG".
+
+	"bitbucket-pr-comment"
+		Report results to Bitbucket Pull Request comments. Results in diff are
+		posted as inline comments and the others are reported to console.
+
+		It needs BitBucket credentials same as bitbucket-code-report even in
+		Bitbucket Pipelines, and BITBUCKET_PR_ID (or CI_PULL_REQUEST).
+
+	"azure-devops-pr"
+		Report results to Azure Repos Pull Request threads. Results in diff
+		files are anchored to their line ranges and the others are posted as
+		general threads.
+
+		1. Set REVIEWDOG_AZURE_DEVOPS_TOKEN with a personal access token
+		(Code: Read & Write scope), or pass System.AccessToken of Azure
+		Pipelines as SYSTEM_ACCESSTOKEN environment variable.
+		2. SYSTEM_COLLECTIONURI, SYSTEM_TEAMPROJECT, BUILD_REPOSITORY_NAME and
+		SYSTEM_PULLREQUEST_PULLREQUESTID are set by Azure Pipelines. Set them
+		manually to run reviewdog outside of Azure Pipelines.
+		3. The diff is computed by local git, which needs the full history.
+		Set fetchDepth: 0 of the checkout step.
+
+		New threads are "active" by default. Set
+		REVIEWDOG_AZURE_DEVOPS_THREAD_STATUS=pending to change it.
+		reviewdog sets its threads to "fixed" when their results are no longer
+		reported, and reactivates them when the results are reported again.
+
+	"gitea-pr-review"
+		Report results to Gitea (or Forgejo) Pull Request review comments.
+
+		1. Set REVIEWDOG_GITEA_API_TOKEN environment variable.
+		2. Set GITEA_API to the API URL of Gitea. In Gitea Actions and Forgejo
+		Actions, it's detected from the server URL of the workflow.
+			$ export GITEA_API="https://gitea.example.com/api/v1"
+
+	"webhook"
+		POST results as JSON to REVIEWDOG_WEBHOOK_URL with build info, results
+		per tool and counts per severity, once all the tools are finished.
+
+		Set REVIEWDOG_WEBHOOK_SECRET to sign the body with HMAC-SHA256 in
+		X-Reviewdog-Signature-256 header ("sha256=<hex digest>").
+		Failed requests (network errors, 429 and 5xx) are retried
+		REVIEWDOG_WEBHOOK_RETRIES times (default 3).
+		Set REVIEWDOG_WEBHOOK_TEMPLATE to a path of Go template file to shape
+		the body, and REVIEWDOG_WEBHOOK_CONTENT_TYPE to change its content type.
+
+		It reports all results by default. Set -filter-mode and -diff to
+		filter results by diff.
+
+	"html"
+		Write results to -output as a self-contained HTML report with counts
+		per tool and severity, a sortable and filterable table of results,
+		and file views with results and suggestions inline.
+
+		It reports all results by default. Set -filter-mode and -diff to
+		filter results by diff.
+
+	"plugin:<name>"
+		Pass results to reviewdog-reporter-<name> executable in $PATH, so
+		that you can use your own reporter without forking reviewdog.
+		See README for the protocol. Results are filtered by diff from the
+		plugin if it provides diff, otherwise same as webhook reporter.
+
 	For GitHub Enterprise and self hosted GitLab, set
 	REVIEWDOG_INSECURE_SKIP_VERIFY to skip verifying SSL (please use this at your own risk)
 		$ export REVIEWDOG_INSECURE_SKIP_VERIFY=true
@@ -183,6 +348,15 @@ const (
 		$ export CI_REPO_NAME="reviewdog" # repository name
 `
 	failOnErrorDoc = `Returns 1 as exit code if any errors/warnings found in input`
+	outputDoc      = `output file path of report for html reporter`
+	targetURLDoc   = `URL of a build or report artifact to link from commit statuses (github-commit-status and gitlab-commit-status reporter)`
+
+	gerritLabelDoc          = `label to vote based on results (e.g. Verified) for gerrit-change-review reporter. It doesn't vote if empty`
+	gerritLabelApproveDoc   = `vote for -gerrit-label when there are no results at or above -gerrit-label-threshold`
+	gerritLabelRejectDoc    = `vote for -gerrit-label when there are results at or above -gerrit-label-threshold`
+	gerritLabelThresholdDoc = `lowest severity of results to reject changes with -gerrit-label ("info","warning","error"). Results without severity use -level`
+	gerritNotifyDoc         = `whom to notify about reviews of gerrit-change-review reporter ("NONE","OWNER","OWNER_REVIEWERS","ALL")`
+	gerritSkipUnresolvedDoc = `skip results which still have unresolved comments on previous patch sets (gerrit-change-review reporter)`
 )
 
 var opt = &option{}
@@ -202,8 +376,17 @@ func init() {
 	flag.StringVar(&opt.level, "level", "error", levelDoc)
 	flag.BoolVar(&opt.guessPullRequest, "guess", false, guessPullRequestDoc)
 	flag.BoolVar(&opt.tee, "tee", false, teeDoc)
+	flag.StringVar(&opt.format, "format", "", formatDoc)
 	flag.Var(&opt.filterMode, "filter-mode", filterModeDoc)
 	flag.BoolVar(&opt.failOnError, "fail-on-error", false, failOnErrorDoc)
+	flag.StringVar(&opt.targetURL, "target-url", "", targetURLDoc)
+	flag.StringVar(&opt.output, "output", "", outputDoc)
+	flag.StringVar(&opt.gerritLabel, "gerrit-label", "", gerritLabelDoc)
+	flag.IntVar(&opt.gerritLabelApprove, "gerrit-label-approve", 1, gerritLabelApproveDoc)
+	flag.IntVar(&opt.gerritLabelReject, "gerrit-label-reject", -1, gerritLabelRejectDoc)
+	flag.StringVar(&opt.gerritLabelThreshold, "gerrit-label-threshold", "error", gerritLabelThresholdDoc)
+	flag.StringVar(&opt.gerritNotify, "gerrit-notify", "", gerritNotifyDoc)
+	flag.BoolVar(&opt.gerritSkipUnresolved, "gerrit-skip-unresolved", false, gerritSkipUnresolvedDoc)
 }
 
 func usage() {
@@ -244,7 +427,6 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 	isProject := len(opt.efms) == 0 && opt.f == ""
 	var projectConf *project.Config
 
-	var cs reviewdog.CommentService
 	var ds reviewdog.DiffService
 
 	if isProject {
@@ -253,15 +435,34 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		if err != nil {
 			return err
 		}
+	}
 
-		cs = reviewdog.NewUnifiedCommentWriter(w)
-	} else {
-		cs = reviewdog.NewRawCommentWriter(w)
+	cs, err := commentWriter(w, opt, isProject, projectConf)
+	if err != nil {
+		return err
 	}
 
 	switch opt.reporter {
 	default:
-		return fmt.Errorf("unknown -reporter: %s", opt.reporter)
+		if !strings.HasPrefix(opt.reporter, "plugin:") {
+			return fmt.Errorf("unknown -reporter: %s", opt.reporter)
+		}
+		// Build info is optional for plugins running locally.
+		build, _, _ := cienv.GetBuildInfo()
+		pr, err := pluginservice.NewReporter(ctx, strings.TrimPrefix(opt.reporter, "plugin:"), build,
+			getRunnersList(opt, projectConf), os.Stderr)
+		if err != nil {
+			return err
+		}
+		cs = reviewdog.MultiCommentService(pr, cs)
+		if pr.HasDiff() && opt.diffCmd == "" {
+			ds = pr
+		} else {
+			ds, err = localDiffOrNoFilter(opt)
+			if err != nil {
+				return err
+			}
+		}
 	case "github-check":
 		return runDoghouse(ctx, r, w, opt, isProject, false)
 	case "github-pr-check":
@@ -287,10 +488,70 @@ github-pr-check reporter as a fallback.
 [1]: https://docs.github.com/en/actions/reference/events-that-trigger-workflows#pull_request_target, 
 [2]: https://help.github.com/en/actions/automating-your-workflow-with-github-actions/development-tools-for-github-actions#logging-commands`)
 			cs = githubutils.NewGitHubActionLogWriter(opt.level)
+			if ss := stepSummaryWriter(); ss != nil {
+				cs = reviewdog.MultiCommentService(cs, ss)
+			}
 		} else {
 			cs = reviewdog.MultiCommentService(gs, cs)
 		}
 		ds = gs
+	case "github-commit-status":
+		token, err := nonEmptyEnv("REVIEWDOG_GITHUB_API_TOKEN")
+		if err != nil {
+			return err
+		}
+		g, isPR, err := cienv.GetBuildInfo()
+		if err != nil {
+			return err
+		}
+		client, err := githubClient(ctx, token)
+		if err != nil {
+			return err
+		}
+		gs, err := githubservice.NewGitHubCommitStatus(ctx, client, g.Owner, g.Repo, g.SHA,
+			getRunnersList(opt, projectConf), opt.level, opt.targetURL)
+		if err != nil {
+			return err
+		}
+		cs = reviewdog.MultiCommentService(gs, cs)
+		if isPR {
+			ds, err = githubservice.NewGitHubPullRequest(client, g.Owner, g.Repo, g.PullRequest, g.SHA)
+			if err != nil {
+				return err
+			}
+		} else {
+			// There is no diff for commit builds, so do not filter results by
+			// diff like github-check reporter.
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		}
+	case "github-actions-summary":
+		path, err := nonEmptyEnv("GITHUB_STEP_SUMMARY")
+		if err != nil {
+			return err
+		}
+		token, err := nonEmptyEnv("REVIEWDOG_GITHUB_API_TOKEN")
+		if err != nil {
+			return err
+		}
+		g, isPR, err := cienv.GetBuildInfo()
+		if err != nil {
+			return err
+		}
+		cs = reviewdog.MultiCommentService(githubutils.NewStepSummaryWriter(path, g.Owner, g.Repo, g.SHA), cs)
+		if isPR {
+			client, err := githubClient(ctx, token)
+			if err != nil {
+				return err
+			}
+			ds, err = githubservice.NewGitHubPullRequest(client, g.Owner, g.Repo, g.PullRequest, g.SHA)
+			if err != nil {
+				return err
+			}
+		} else {
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		}
 	case "gitlab-mr-discussion":
 		build, cli, err := gitlabBuildWithClient()
 		if err != nil {
@@ -301,16 +562,45 @@ github-pr-check reporter as a fallback.
 			return nil
 		}
 
-		gc, err := gitlabservice.NewGitLabMergeRequestDiscussionCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		gc, err := gitlabservice.NewGitLabMergeRequestDiscussionCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA,
+			getRunnersList(opt, projectConf), os.Getenv("REVIEWDOG_GITLAB_REOPEN_DISCUSSIONS") == "true")
 		if err != nil {
 			return err
 		}
 
 		cs = reviewdog.MultiCommentService(gc, cs)
+		if os.Getenv("REVIEWDOG_GITLAB_COMMIT_STATUS") == "true" {
+			gs, err := gitlabCommitStatus(ctx, opt, build, cli, getRunnersList(opt, projectConf))
+			if err != nil {
+				return err
+			}
+			cs = reviewdog.MultiCommentService(gs, cs)
+		}
 		ds, err = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
 		if err != nil {
 			return err
 		}
+	case "gitlab-commit-status":
+		build, cli, err := gitlabBuildWithClient()
+		if err != nil {
+			return err
+		}
+		gs, err := gitlabCommitStatus(ctx, opt, build, cli, getRunnersList(opt, projectConf))
+		if err != nil {
+			return err
+		}
+		cs = reviewdog.MultiCommentService(gs, cs)
+		if build.PullRequest != 0 {
+			ds, err = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+			if err != nil {
+				return err
+			}
+		} else {
+			// There is no diff for commit builds, so do not filter results by
+			// diff like github-commit-status reporter.
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		}
 	case "gitlab-mr-commit":
 		build, cli, err := gitlabBuildWithClient()
 		if err != nil {
@@ -321,7 +611,8 @@ github-pr-check reporter as a fallback.
 			return nil
 		}
 
-		gc, err := gitlabservice.NewGitLabMergeRequestCommitCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		gc, err := gitlabservice.NewGitLabMergeRequestCommitCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA,
+			os.Getenv("REVIEWDOG_GITLAB_MR_COMMIT_HEAD_ONLY") == "true")
 		if err != nil {
 			return err
 		}
@@ -332,11 +623,24 @@ github-pr-check reporter as a fallback.
 			return err
 		}
 	case "gerrit-change-review":
-		b, cli, err := gerritBuildWithClient()
+		b, cli, restCli, err := gerritBuildWithClient()
 		if err != nil {
 			return err
 		}
-		gc, err := gerritservice.NewChangeReviewCommenter(cli, b.GerritChangeID, b.GerritRevisionID)
+		threshold, ok := rdf.Severity_value[strings.ToUpper(opt.gerritLabelThreshold)]
+		if !ok || threshold == int32(rdf.Severity_UNKNOWN_SEVERITY) {
+			return fmt.Errorf("invalid -gerrit-label-threshold: %q", opt.gerritLabelThreshold)
+		}
+		gc, err := gerritservice.NewChangeReviewCommenter(restCli, b.GerritChangeID, b.GerritRevisionID,
+			getRunnersList(opt, projectConf), gerritservice.ReviewOption{
+				Label:          opt.gerritLabel,
+				Approve:        opt.gerritLabelApprove,
+				Reject:         opt.gerritLabelReject,
+				Threshold:      rdf.Severity(threshold),
+				Level:          opt.level,
+				Notify:         strings.ToUpper(opt.gerritNotify),
+				SkipUnresolved: opt.gerritSkipUnresolved,
+			})
 		if err != nil {
 			return err
 		}
@@ -354,21 +658,130 @@ github-pr-check reporter as a fallback.
 		}
 		ctx = ct
 
+		reportTypes, err := bbservice.ParseReportTypes(os.Getenv("REVIEWDOG_BITBUCKET_REPORT_TYPES"))
+		if err != nil {
+			return fmt.Errorf("REVIEWDOG_BITBUCKET_REPORT_TYPES is invalid: %w", err)
+		}
+		annotationTypes, err := bbservice.ParseAnnotationTypes(os.Getenv("REVIEWDOG_BITBUCKET_ANNOTATION_TYPES"))
+		if err != nil {
+			return fmt.Errorf("REVIEWDOG_BITBUCKET_ANNOTATION_TYPES is invalid: %w", err)
+		}
+
 		cs = bbservice.NewReportAnnotator(client,
-			build.Owner, build.Repo, build.SHA, getRunnersList(opt, projectConf))
+			build.Owner, build.Repo, build.SHA, getRunnersList(opt, projectConf),
+			bbservice.ReportOption{ReportTypes: reportTypes, AnnotationTypes: annotationTypes, Level: opt.level})
 
-		if !(opt.filterMode == filter.ModeDefault || opt.filterMode == filter.ModeNoFilter) {
+		switch {
+		case opt.filterMode == filter.ModeDefault || opt.filterMode == filter.ModeNoFilter:
 			// by default scan whole project with out diff (filter.ModeNoFilter)
-			// Bitbucket pipelines doesn't give an easy way to know
-			// which commit run pipeline before so we can compare between them
-			// however once PR is opened, Bitbucket Reports UI will do automatic
+			// once PR is opened, Bitbucket Reports UI will do automatic
 			// filtering of annotations dividing them in two groups:
 			// - This pull request (10)
 			// - All (50)
-			log.Printf("reviewdog: [bitbucket-code-report] supports only with filter.ModeNoFilter for now")
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		case build.PullRequest != 0 && bitbucketHasCredentials():
+			// Bitbucket pipelines doesn't give an easy way to know
+			// which commit run pipeline before, so filter by PR diff
+			// fetched from pull request API which requires credentials
+			ds = bbservice.NewPullRequestDiff(bitbucketPullRequestClient(),
+				build.Owner, build.Repo, build.PullRequest)
+		default:
+			log.Printf("reviewdog: [bitbucket-code-report] supports only filter.ModeNoFilter " +
+				"without BITBUCKET_PR_ID or Bitbucket credentials")
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		}
+	case "bitbucket-pr-comment":
+		build, _, ct, err := bitbucketBuildWithClient(ctx)
+		if err != nil {
+			return err
+		}
+		ctx = ct
+		if build.PullRequest == 0 {
+			fmt.Fprintln(os.Stderr, "this is not PullRequest build.")
+			return nil
+		}
+
+		bc, err := bbservice.NewPullRequestCommenter(bitbucketPullRequestClient(),
+			build.Owner, build.Repo, build.PullRequest)
+		if err != nil {
+			return err
+		}
+
+		cs = reviewdog.MultiCommentService(bc, cs)
+		ds = bc
+	case "azure-devops-pr":
+		build, cli, err := azureDevOpsBuildWithClient()
+		if err != nil {
+			return err
+		}
+		if build.PullRequest == 0 {
+			fmt.Fprintln(os.Stderr, "this is not PullRequest build.")
+			return nil
+		}
+
+		ac, err := azuredevopsservice.NewPullRequestCommenter(cli, build.PullRequest,
+			getRunnersList(opt, projectConf), os.Getenv("REVIEWDOG_AZURE_DEVOPS_THREAD_STATUS"))
+		if err != nil {
+			return err
+		}
+
+		cs = reviewdog.MultiCommentService(ac, cs)
+		ds = azuredevopsservice.NewPullRequestDiff(cli, build.PullRequest)
+	case "gitea-pr-review":
+		build, cli, err := giteaBuildWithClient()
+		if err != nil {
+			return err
+		}
+		if build.PullRequest == 0 {
+			fmt.Fprintln(os.Stderr, "this is not PullRequest build.")
+			return nil
+		}
+
+		gp, err := giteaservice.NewGiteaPullRequest(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		if err != nil {
+			return err
+		}
+
+		cs = reviewdog.MultiCommentService(gp, cs)
+		ds = gp
+	case "webhook":
+		wh, err := webhook(opt, projectConf)
+		if err != nil {
+			return err
+		}
+		cs = reviewdog.MultiCommentService(wh, cs)
+		ds, err = localDiffOrNoFilter(opt)
+		if err != nil {
+			return err
+		}
+	case "html":
+		if opt.output == "" {
+			return errors.New("-output is required for html reporter")
+		}
+		// Build info is optional for reports of local runs.
+		build, _, _ := cienv.GetBuildInfo()
+		cs = reviewdog.MultiCommentService(htmlservice.NewReport(opt.output, build, getRunnersList(opt, projectConf)), cs)
+		var err error
+		ds, err = localDiffOrNoFilter(opt)
+		if err != nil {
+			return err
+		}
+	case "gitlab-code-quality":
+		path := os.Getenv("REVIEWDOG_GITLAB_CODE_QUALITY_REPORT")
+		if path == "" {
+			path = "gl-code-quality-report.json"
+		}
+		r, err := gitlabservice.NewGitLabCodeQualityReport(path, opt.level)
+		if err != nil {
+			return err
+		}
+		cs = reviewdog.MultiCommentService(r, cs)
+		ds, err = gitlabCodeQualityDiff(opt)
+		if err != nil {
+			return err
 		}
-		opt.filterMode = filter.ModeNoFilter
-		ds = &reviewdog.EmptyDiff{}
 	case "local":
 		if opt.diffCmd == "" && opt.filterMode == filter.ModeNoFilter {
 			ds = &reviewdog.EmptyDiff{}
@@ -421,6 +834,73 @@ func sortedFmts(fs fmts.Fmts) []*fmts.Fmt {
 	return r
 }
 
+// commentWriter returns a comment service which writes results to w in
+// -format.
+func commentWriter(w io.Writer, opt *option, isProject bool, projectConf *project.Config) (reviewdog.CommentService, error) {
+	switch opt.format {
+	case "":
+		if isProject {
+			return reviewdog.NewUnifiedCommentWriter(w), nil
+		}
+		return reviewdog.NewRawCommentWriter(w), nil
+	case "unified":
+		return reviewdog.NewUnifiedCommentWriter(w), nil
+	case "pretty":
+		return reviewdog.NewPrettyCommentWriter(w, getRunnersList(opt, projectConf), colorOutput(w)), nil
+	default:
+		return nil, fmt.Errorf("unknown -format: %s", opt.format)
+	}
+}
+
+// colorOutput returns true if w is a terminal and NO_COLOR is not set.
+// https://no-color.org/
+func colorOutput(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// localDiffOrNoFilter returns a diff service of -diff. It reports all results
+// without -diff by default, and requires -diff for other filter modes.
+func localDiffOrNoFilter(opt *option) (reviewdog.DiffService, error) {
+	if opt.diffCmd != "" {
+		return diffService(opt.diffCmd, opt.diffStrip)
+	}
+	if opt.filterMode == filter.ModeDefault || opt.filterMode == filter.ModeNoFilter {
+		opt.filterMode = filter.ModeNoFilter
+		return &reviewdog.EmptyDiff{}, nil
+	}
+	return nil, fmt.Errorf("-filter-mode=%s requires -diff for %s reporter", opt.filterMode.String(), opt.reporter)
+}
+
+// gitlabCodeQualityDiff returns a diff service for gitlab-code-quality
+// reporter. GitLab compares Code Quality reports between the source and
+// target branches by itself, so it reports all results by default.
+func gitlabCodeQualityDiff(opt *option) (reviewdog.DiffService, error) {
+	if opt.diffCmd != "" {
+		return diffService(opt.diffCmd, opt.diffStrip)
+	}
+	if opt.filterMode == filter.ModeDefault || opt.filterMode == filter.ModeNoFilter {
+		opt.filterMode = filter.ModeNoFilter
+		return &reviewdog.EmptyDiff{}, nil
+	}
+	// Available in merge request pipelines.
+	base := os.Getenv("CI_MERGE_REQUEST_DIFF_BASE_SHA")
+	if base == "" {
+		return nil, fmt.Errorf("-filter-mode=%s requires -diff or CI_MERGE_REQUEST_DIFF_BASE_SHA for gitlab-code-quality reporter", opt.filterMode.String())
+	}
+	return diffService("git diff "+base, 1)
+}
+
 func diffService(s string, strip int) (reviewdog.DiffService, error) {
 	cmds, err := shellwords.Parse(s)
 	if err != nil {
@@ -516,18 +996,90 @@ func getPullRequestIDByBranchOrCommit(ctx context.Context, client *github.Client
 	return *pullRequests.Issues[0].Number, nil
 }
 
+// stepSummaryWriter returns a writer for the job summary of GitHub Actions
+// or nil if job summary is not available.
+func stepSummaryWriter() *githubutils.StepSummaryWriter {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	g, _, err := cienv.GetBuildInfo()
+	if err != nil {
+		return nil
+	}
+	return githubutils.NewStepSummaryWriter(path, g.Owner, g.Repo, g.SHA)
+}
+
 func githubClient(ctx context.Context, token string) (*github.Client, error) {
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, newHTTPClient())
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
+	tc.Transport = githubutils.NewRetryTransport(tc.Transport)
 	client := github.NewClient(tc)
 	var err error
 	client.BaseURL, err = githubBaseURL()
 	return client, err
 }
 
+// githubAppClient returns a GitHub client authenticated as a GitHub App
+// installation. It exchanges a JWT signed with the App private key for an
+// installation token (and refreshes it) by itself, so users don't need to
+// generate installation tokens outside reviewdog (e.g. in Jenkins).
+// If REVIEWDOG_GITHUB_APP_INSTALLATION_ID is empty, it finds the installation
+// for the repository of the current build.
+func githubAppClient(ctx context.Context) (*github.Client, error) {
+	appID, err := strconv.ParseInt(os.Getenv("REVIEWDOG_GITHUB_APP_ID"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("REVIEWDOG_GITHUB_APP_ID is invalid: %w", err)
+	}
+	keyPath, err := nonEmptyEnv("REVIEWDOG_GITHUB_APP_PRIVATE_KEY_PATH")
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := githubBaseURL()
+	if err != nil {
+		return nil, err
+	}
+	tr := newHTTPClient().Transport
+	atr, err := ghinstallation.NewAppsTransportKeyFromFile(tr, appID, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	atr.BaseURL = strings.TrimSuffix(baseURL.String(), "/")
+
+	var installationID int64
+	if id := os.Getenv("REVIEWDOG_GITHUB_APP_INSTALLATION_ID"); id != "" {
+		installationID, err = strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("REVIEWDOG_GITHUB_APP_INSTALLATION_ID is invalid: %w", err)
+		}
+	} else {
+		g, _, err := cienv.GetBuildInfo()
+		if err != nil {
+			return nil, err
+		}
+		appCli := github.NewClient(&http.Client{Transport: atr})
+		appCli.BaseURL = baseURL
+		installation, _, err := appCli.Apps.FindRepositoryInstallation(ctx, g.Owner, g.Repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find GitHub App installation for %s/%s: %w", g.Owner, g.Repo, err)
+		}
+		installationID = installation.GetID()
+	}
+
+	itr := ghinstallation.NewFromAppsTransport(atr, installationID)
+	client := github.NewClient(&http.Client{Transport: githubutils.NewRetryTransport(itr)})
+	client.BaseURL = baseURL
+	return client, nil
+}
+
+// useGitHubApp returns true if GitHub App credentials are provided.
+func useGitHubApp() bool {
+	return os.Getenv("REVIEWDOG_GITHUB_APP_ID") != ""
+}
+
 const defaultGitHubAPI = "https://api.github.com/"
 
 func githubBaseURL() (*url.URL, error) {
@@ -583,31 +1135,55 @@ func gitlabBuildWithClient() (*cienv.BuildInfo, *gitlab.Client, error) {
 	return g, client, err
 }
 
-func gerritBuildWithClient() (*cienv.BuildInfo, *gerrit.Client, error) {
+// gitlabCommitStatus returns a commit status service for the current pipeline.
+// Statuses link to the job by default.
+func gitlabCommitStatus(ctx context.Context, opt *option, build *cienv.BuildInfo, cli *gitlab.Client, runners []string) (*gitlabservice.CommitStatus, error) {
+	targetURL := opt.targetURL
+	if targetURL == "" {
+		targetURL = os.Getenv("CI_JOB_URL")
+	}
+	pipelineID, _ := strconv.Atoi(os.Getenv("CI_PIPELINE_ID"))
+	return gitlabservice.NewGitLabCommitStatus(ctx, cli, build.Owner, build.Repo, build.SHA, build.Branch, pipelineID,
+		runners, opt.level, targetURL)
+}
+
+// gerritBuildWithClient returns a client of golang.org/x/build/gerrit and a
+// client for REST APIs which it doesn't support, with the same authentication.
+func gerritBuildWithClient() (*cienv.BuildInfo, *gerrit.Client, *gerritservice.Client, error) {
 	buildInfo, err := cienv.GetGerritBuildInfo()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	gerritAddr := os.Getenv("GERRIT_ADDRESS")
+	log.Printf("reviewdog: Gerrit change %s (revision %s) of %s is detected from %s",
+		buildInfo.GerritChangeID, buildInfo.GerritRevisionID, buildInfo.GerritAddress, buildInfo.GerritSource)
+
+	gerritAddr := buildInfo.GerritAddress
 	if gerritAddr == "" {
-		return nil, nil, errors.New("cannot get gerrit host address from environment variable. Set GERRIT_ADDRESS ?")
+		return nil, nil, nil, errors.New("cannot get gerrit host address from environment variable. Set GERRIT_ADDRESS ?")
 	}
 
 	username := os.Getenv("GERRIT_USERNAME")
 	password := os.Getenv("GERRIT_PASSWORD")
 	if username != "" && password != "" {
 		client := gerrit.NewClient(gerritAddr, gerrit.BasicAuth(username, password))
-		return buildInfo, client, nil
+		restClient := gerritservice.NewClient(newHTTPClient(), gerritAddr, gerritservice.BasicAuth(username, password))
+		return buildInfo, client, restClient, nil
 	}
 
 	if useGitCookiePath := os.Getenv("GERRIT_GIT_COOKIE_PATH"); useGitCookiePath != "" {
+		auth, err := gerritservice.GitCookieFileAuth(useGitCookiePath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 		client := gerrit.NewClient(gerritAddr, gerrit.GitCookieFileAuth(useGitCookiePath))
-		return buildInfo, client, nil
+		restClient := gerritservice.NewClient(newHTTPClient(), gerritAddr, auth)
+		return buildInfo, client, restClient, nil
 	}
 
 	client := gerrit.NewClient(gerritAddr, gerrit.NoAuth)
-	return buildInfo, client, nil
+	restClient := gerritservice.NewClient(newHTTPClient(), gerritAddr, gerritservice.NoAuth)
+	return buildInfo, client, restClient, nil
 }
 
 func bitbucketBuildWithClient(ctx context.Context) (*cienv.BuildInfo, bbservice.APIClient, context.Context, error) {
@@ -636,6 +1212,116 @@ func bitbucketBuildWithClient(ctx context.Context) (*cienv.BuildInfo, bbservice.
 	return build, client, ctx, nil
 }
 
+// bitbucketHasCredentials returns true if Bitbucket API credentials are set.
+// Code Insights API is available without credentials in Bitbucket Pipelines,
+// but pull request API isn't.
+func bitbucketHasCredentials() bool {
+	return (os.Getenv("BITBUCKET_USER") != "" && os.Getenv("BITBUCKET_PASSWORD") != "") ||
+		os.Getenv("BITBUCKET_ACCESS_TOKEN") != ""
+}
+
+// bitbucketPullRequestClient returns a client for pull request API of
+// Bitbucket Server if BITBUCKET_SERVER_URL is set, otherwise Bitbucket Cloud.
+// Credentials are passed via context built by bitbucketBuildWithClient.
+func bitbucketPullRequestClient() bbservice.PullRequestClient {
+	if bbServerURL := os.Getenv("BITBUCKET_SERVER_URL"); bbServerURL != "" {
+		return bbservice.NewServerPullRequestClient(bbServerURL)
+	}
+	return bbservice.NewCloudPullRequestClient()
+}
+
+// azureDevOpsBuildWithClient returns build info and a client authorized with
+// REVIEWDOG_AZURE_DEVOPS_TOKEN (personal access token) or SYSTEM_ACCESSTOKEN.
+func azureDevOpsBuildWithClient() (*cienv.BuildInfo, *azuredevopsservice.Client, error) {
+	build, err := cienv.GetAzureDevOpsBuildInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var auth string
+	if token := os.Getenv("REVIEWDOG_AZURE_DEVOPS_TOKEN"); token != "" {
+		auth = azuredevopsservice.BasicAuth(token)
+	} else if token := os.Getenv("SYSTEM_ACCESSTOKEN"); token != "" {
+		auth = azuredevopsservice.BearerAuth(token)
+	} else {
+		return nil, nil, errors.New("environment variable $REVIEWDOG_AZURE_DEVOPS_TOKEN or $SYSTEM_ACCESSTOKEN is not set")
+	}
+
+	cli := azuredevopsservice.NewClient(newHTTPClient(), build.AzureDevOpsCollectionURI, build.Owner, build.Repo, auth)
+	return build, cli, nil
+}
+
+func giteaBuildWithClient() (*cienv.BuildInfo, *giteaservice.Client, error) {
+	token, err := nonEmptyEnv("REVIEWDOG_GITEA_API_TOKEN")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g, _, err := cienv.GetBuildInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	baseURL, err := giteaBaseURL()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return g, giteaservice.NewClient(newHTTPClient(), baseURL, token), nil
+}
+
+// giteaBaseURL returns GITEA_API, or the API URL of the Gitea (or Forgejo)
+// instance running the workflow.
+func giteaBaseURL() (string, error) {
+	if giteaAPI := os.Getenv("GITEA_API"); giteaAPI != "" {
+		return giteaAPI, nil
+	}
+	if serverURL := cienv.GetGiteaServerURL(); serverURL != "" {
+		return strings.TrimSuffix(serverURL, "/") + "/api/v1", nil
+	}
+	return "", errors.New("cannot get Gitea API URL from environment variable. Set GITEA_API ?")
+}
+
+// webhook returns a webhook service configured by REVIEWDOG_WEBHOOK_*
+// environment variables. Build info is optional as it may run locally.
+func webhook(opt *option, conf *project.Config) (*webhookservice.Webhook, error) {
+	webhookURL, err := nonEmptyEnv("REVIEWDOG_WEBHOOK_URL")
+	if err != nil {
+		return nil, err
+	}
+
+	whOpt := webhookservice.Option{
+		Secret:      os.Getenv("REVIEWDOG_WEBHOOK_SECRET"),
+		Retries:     webhookservice.DefaultRetries,
+		ContentType: os.Getenv("REVIEWDOG_WEBHOOK_CONTENT_TYPE"),
+	}
+	if retries := os.Getenv("REVIEWDOG_WEBHOOK_RETRIES"); retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("REVIEWDOG_WEBHOOK_RETRIES is invalid: %q", retries)
+		}
+		whOpt.Retries = n
+	}
+	if path := os.Getenv("REVIEWDOG_WEBHOOK_TEMPLATE"); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read REVIEWDOG_WEBHOOK_TEMPLATE: %w", err)
+		}
+		whOpt.Template, err = webhookservice.ParseTemplate(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("REVIEWDOG_WEBHOOK_TEMPLATE is invalid: %w", err)
+		}
+	}
+
+	build, _, err := cienv.GetBuildInfo()
+	if err != nil {
+		log.Printf("reviewdog: webhook payload doesn't have build info: %v", err)
+		build = nil
+	}
+
+	return webhookservice.NewWebhook(newHTTPClient(), webhookURL, build, getRunnersList(opt, conf), whOpt), nil
+}
+
 func fetchMergeRequestIDFromCommit(cli *gitlab.Client, projectID, sha string) (id int, err error) {
 	// https://docs.gitlab.com/ce/api/merge_requests.html#list-project-merge-requests
 	opt := &gitlab.ListProjectMergeRequestsOptions{
@@ -772,16 +1458,27 @@ func buildRunnersMap(runners string) map[string]bool {
 
 func getRunnersList(opt *option, conf *project.Config) []string {
 	if len(opt.runners) > 0 { // if runners explicitly defined, use them
-		return strings.Split(opt.runners, ",")
+		list := make([]string, 0)
+		for name := range buildRunnersMap(opt.runners) {
+			list = append(list, name)
+		}
+		sort.Strings(list)
+		return list
 	}
 
 	if conf != nil { // if this is a Project run, and no explicitly provided runners
 		// if no runners explicitly provided
-		// get all runners from config
+		// get all runners from config. Results are reported with the runner
+		// name, which can differ from the key in the config.
 		list := make([]string, 0, len(conf.Runner))
-		for runner := range conf.Runner {
-			list = append(list, runner)
+		for key, runner := range conf.Runner {
+			name := runner.Name
+			if name == "" {
+				name = key
+			}
+			list = append(list, name)
 		}
+		sort.Strings(list)
 		return list
 	}
 
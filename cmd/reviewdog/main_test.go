@@ -9,8 +9,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/reviewdog/reviewdog/commands"
 	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/project"
 )
 
 func TestRun_local(t *testing.T) {
@@ -125,6 +128,46 @@ func TestRun_local_tee(t *testing.T) {
 	}
 }
 
+func TestRun_local_pretty(t *testing.T) {
+	f, err := ioutil.TempFile("", "reviewdog-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	defer os.Remove(f.Name())
+	f.WriteString("line1\nline2\n")
+
+	fname := f.Name()
+	stdin := fname + "(2,3): message"
+	want := fname + `
+` + fname + `:2:3: message [tool]
+  2 | line2
+    |   ^
+
+1 result
+`
+
+	opt := &option{
+		efms:       strslice([]string{`%f(%l,%c): %m`}),
+		name:       "tool",
+		reporter:   "local",
+		format:     "pretty",
+		filterMode: filter.ModeNoFilter,
+	}
+	stdout := new(bytes.Buffer)
+	if err := run(strings.NewReader(stdin), stdout, opt); err != nil {
+		t.Error(err)
+	}
+	if got := stdout.String(); got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+
+	opt.format = "unknown"
+	if err := run(strings.NewReader(stdin), stdout, opt); err == nil {
+		t.Error("got no error for unknown -format")
+	}
+}
+
 func TestRun_project(t *testing.T) {
 	t.Run("diff command is empty", func(t *testing.T) {
 		opt := &option{
@@ -217,3 +260,25 @@ func TestRun_version(t *testing.T) {
 		t.Errorf("version = %v, want %v", got, commands.Version)
 	}
 }
+
+func TestGetRunnersList(t *testing.T) {
+	conf := &project.Config{Runner: map[string]*project.Runner{
+		"golint": {},
+		"vet":    {Name: "govet"},
+	}}
+	tests := []struct {
+		opt  *option
+		conf *project.Config
+		want []string
+	}{
+		{opt: &option{runners: "vet, golint,"}, conf: conf, want: []string{"golint", "vet"}},
+		{opt: &option{}, conf: conf, want: []string{"golint", "govet"}},
+		{opt: &option{name: "tool"}, want: []string{"tool"}},
+	}
+	for _, tt := range tests {
+		got := getRunnersList(tt.opt, tt.conf)
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("getRunnersList(%+v) has diff:\n%s", tt.opt, diff)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package reviewdog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ BulkCommentService = &PrettyCommentWriter{}
+
+// maxPrettySourceLines is the max number of source lines shown per result.
+const maxPrettySourceLines = 5
+
+// ANSI escape sequences used by PrettyCommentWriter.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// PrettyCommentWriter is comment writer which writes results to given writer
+// in compiler-like format with source lines of the results. Results are
+// grouped by file and sorted by position.
+//
+// Format:
+//   <file>
+//   <file>:<lnum>:<col>: <severity>[<code>]: <message> [<tool name>]
+//      <lnum> | <source line>
+//             |   ^^^^
+//             = suggestion:
+//      <lnum> - <source line>
+//             + <suggested line>
+//
+// Results are written once all the runners are flushed, or on every Flush if
+// runners are not given.
+type PrettyCommentWriter struct {
+	w     io.Writer
+	color bool
+
+	mu       sync.Mutex
+	comments []*Comment
+
+	runners []string
+	flushed int
+
+	// readFile reads source files. Source lines in diff are used instead if
+	// it fails.
+	readFile func(string) ([]byte, error)
+}
+
+// NewPrettyCommentWriter returns a new PrettyCommentWriter. It colors output
+// by severity with ANSI escape sequences if color is true.
+func NewPrettyCommentWriter(w io.Writer, runners []string, color bool) *PrettyCommentWriter {
+	p := &PrettyCommentWriter{w: w, color: color, readFile: os.ReadFile}
+	for _, r := range runners {
+		if r != "" {
+			p.runners = append(p.runners, r)
+		}
+	}
+	return p
+}
+
+// Post accepts a comment and holds it. Flush method actually writes results.
+func (p *PrettyCommentWriter) Post(_ context.Context, c *Comment) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.comments = append(p.comments, c)
+	return nil
+}
+
+// Flush writes the held results once all the runners are flushed.
+func (p *PrettyCommentWriter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Flush is called per runner. Results of other runners may not be posted
+	// yet until all of them are flushed.
+	p.flushed++
+	if len(p.runners) > 0 && p.flushed < len(p.runners) {
+		return nil
+	}
+	if len(p.comments) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	p.writeResults(&sb, p.comments)
+	p.comments = nil
+	_, err := io.WriteString(p.w, sb.String())
+	return err
+}
+
+func (p *PrettyCommentWriter) writeResults(sb *strings.Builder, comments []*Comment) {
+	files := make(map[string][]*Comment)
+	for _, c := range comments {
+		path := c.Result.Diagnostic.GetLocation().GetPath()
+		files[path] = append(files[path], c)
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	// Results without file come first.
+	sort.Strings(paths)
+
+	counts := make(serviceutil.SeverityCounts)
+	for _, path := range paths {
+		cs := files[path]
+		sort.SliceStable(cs, func(i, j int) bool {
+			si := cs[i].Result.Diagnostic.GetLocation().GetRange().GetStart()
+			sj := cs[j].Result.Diagnostic.GetLocation().GetRange().GetStart()
+			if si.GetLine() != sj.GetLine() {
+				return si.GetLine() < sj.GetLine()
+			}
+			return si.GetColumn() < sj.GetColumn()
+		})
+		if path != "" {
+			sb.WriteString(p.paint(path, ansiBold, ansiCyan))
+			sb.WriteString("\n")
+		}
+		lines := p.fileLines(path)
+		for _, c := range cs {
+			p.writeResult(sb, c, lines)
+			sb.WriteString("\n")
+			counts[c.Result.Diagnostic.GetSeverity()]++
+		}
+	}
+	p.writeSummary(sb, counts)
+}
+
+// fileLines returns lines of the file, or nil if it cannot be read.
+func (p *PrettyCommentWriter) fileLines(path string) []string {
+	if path == "" {
+		return nil
+	}
+	b, err := p.readFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(b), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines
+}
+
+func (p *PrettyCommentWriter) writeResult(sb *strings.Builder, c *Comment, fileLines []string) {
+	d := c.Result.Diagnostic
+	loc := d.GetLocation()
+	start := loc.GetRange().GetStart()
+
+	var header string
+	if loc.GetPath() != "" {
+		header = loc.GetPath()
+		if start.GetLine() > 0 {
+			header += fmt.Sprintf(":%d", start.GetLine())
+			if start.GetColumn() > 0 {
+				header += fmt.Sprintf(":%d", start.GetColumn())
+			}
+		}
+		header = p.paint(header, ansiBold) + ": "
+	}
+	sb.WriteString(header)
+	if sv := d.GetSeverity(); sv != rdf.Severity_UNKNOWN_SEVERITY {
+		label := serviceutil.SeverityName(sv)
+		if code := d.GetCode().GetValue(); code != "" {
+			label += "[" + code + "]"
+		}
+		sb.WriteString(p.paint(label, ansiBold, severityColor(d.GetSeverity())) + ": ")
+	} else if code := d.GetCode().GetValue(); code != "" {
+		sb.WriteString(p.paint(code, ansiBold) + ": ")
+	}
+	sb.WriteString(d.GetMessage())
+	sb.WriteString(" " + p.paint("["+c.ToolName+"]", ansiBold))
+	sb.WriteString("\n")
+
+	if start.GetLine() == 0 {
+		return
+	}
+	// The files may have been changed since the old side of diff.
+	if loc.GetOld() {
+		fileLines = nil
+	}
+	src := &prettySource{fileLines: fileLines, diffLines: c.Result.SourceLines}
+	lastLine := int(start.GetLine())
+	for _, s := range d.GetSuggestions() {
+		if l := int(s.GetRange().GetEnd().GetLine()); l > lastLine {
+			lastLine = l
+		}
+	}
+	if l := int(loc.GetRange().GetEnd().GetLine()); l > lastLine {
+		lastLine = l
+	}
+	g := &prettyGutter{width: len(strconv.Itoa(lastLine)), p: p}
+
+	p.writeSource(sb, g, src, loc.GetRange(), severityColor(d.GetSeverity()))
+	for _, s := range d.GetSuggestions() {
+		p.writeSuggestion(sb, g, src, s)
+	}
+}
+
+// writeSource writes the source lines of the range with underlines of the
+// columns of the range.
+func (p *PrettyCommentWriter) writeSource(sb *strings.Builder, g *prettyGutter, src *prettySource, r *rdf.Range, color string) {
+	start, end := r.GetStart(), r.GetEnd()
+	startLine := int(start.GetLine())
+	endLine := int(end.GetLine())
+	if endLine < startLine {
+		endLine = startLine
+	}
+	for lnum := startLine; lnum <= endLine; lnum++ {
+		if lnum-startLine == maxPrettySourceLines {
+			g.write(sb, "", "|", "...")
+			break
+		}
+		line, ok := src.line(lnum)
+		if !ok {
+			continue
+		}
+		g.write(sb, strconv.Itoa(lnum), "|", line)
+		if start.GetColumn() == 0 {
+			// Line-wise range doesn't have columns to underline.
+			continue
+		}
+		// Byte offsets of the underline in the line.
+		from, to := 0, len(line)
+		if lnum == startLine {
+			from = serviceutil.ColumnOffset(start.GetColumn(), line)
+		}
+		if lnum == int(end.GetLine()) && end.GetColumn() > 0 {
+			to = serviceutil.ColumnOffset(end.GetColumn(), line)
+		} else if lnum == startLine && end.GetLine() == 0 {
+			// Zero-length range.
+			to = from
+		}
+		if lnum != startLine {
+			// Don't underline the indentation of following lines.
+			from = len(line) - len(strings.TrimLeft(line, " \t"))
+		}
+		if to < from {
+			to = from
+		}
+		g.write(sb, "", "|", underline(line, from, to, p, color))
+	}
+}
+
+// writeSuggestion writes the suggestion as a diff of the source lines.
+func (p *PrettyCommentWriter) writeSuggestion(sb *strings.Builder, g *prettyGutter, src *prettySource, s *rdf.Suggestion) {
+	sd := serviceutil.BuildSuggestionDiff(s, src.line)
+	g.write(sb, "", "=", "suggestion:")
+	for i, l := range sd.Old {
+		g.write(sb, strconv.Itoa(sd.StartLine+i), p.paint("-", ansiRed), p.paint(l, ansiRed))
+	}
+	for _, l := range sd.New {
+		g.write(sb, "", p.paint("+", ansiGreen), p.paint(l, ansiGreen))
+	}
+}
+
+func (p *PrettyCommentWriter) writeSummary(sb *strings.Builder, counts serviceutil.SeverityCounts) {
+	var details []string
+	for _, s := range []rdf.Severity{rdf.Severity_ERROR, rdf.Severity_WARNING, rdf.Severity_INFO} {
+		if n := counts[s]; n > 0 {
+			details = append(details, p.paint(plural(n, serviceutil.SeverityName(s)), ansiBold, severityColor(s)))
+		}
+	}
+	sb.WriteString(plural(counts.Total(), "result"))
+	if len(details) > 0 {
+		sb.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	sb.WriteString("\n")
+}
+
+// paint wraps s with the ANSI escape sequences if color is enabled.
+func (p *PrettyCommentWriter) paint(s string, codes ...string) string {
+	if !p.color || s == "" {
+		return s
+	}
+	return strings.Join(codes, "") + s + ansiReset
+}
+
+// prettySource provides source lines from the file, or from diff if the file
+// is not available.
+type prettySource struct {
+	fileLines []string
+	diffLines map[int]string
+}
+
+func (s *prettySource) line(lnum int) (string, bool) {
+	if s.fileLines != nil {
+		if lnum < 1 || lnum > len(s.fileLines) {
+			return "", false
+		}
+		return s.fileLines[lnum-1], true
+	}
+	l, ok := s.diffLines[lnum]
+	return l, ok
+}
+
+// prettyGutter writes lines with a gutter of line numbers.
+type prettyGutter struct {
+	width int
+	p     *PrettyCommentWriter
+}
+
+func (g *prettyGutter) write(sb *strings.Builder, lnum, sep, text string) {
+	fmt.Fprintf(sb, "  %s %s", g.p.paint(fmt.Sprintf("%*s", g.width, lnum), ansiBold, ansiBlue), g.p.paint(sep, ansiBold, ansiBlue))
+	if text != "" {
+		sb.WriteString(" " + text)
+	}
+	sb.WriteString("\n")
+}
+
+// underline returns carets under line[from:to]. Zero-length range has a caret
+// at from. Tabs in the line are kept to align the carets.
+func underline(line string, from, to int, p *PrettyCommentWriter, color string) string {
+	var sb strings.Builder
+	for _, r := range line[:from] {
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	n := utf8.RuneCountInString(line[from:to])
+	if n == 0 {
+		n = 1
+	}
+	return sb.String() + p.paint(strings.Repeat("^", n), ansiBold, color)
+}
+
+func severityColor(s rdf.Severity) string {
+	switch s {
+	case rdf.Severity_ERROR:
+		return ansiRed
+	case rdf.Severity_WARNING:
+		return ansiYellow
+	case rdf.Severity_INFO:
+		return ansiBlue
+	default:
+		return ""
+	}
+}
+
+func plural(n int, s string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, s)
+	}
+	return fmt.Sprintf("%d %ss", n, s)
+}
@@ -0,0 +1,182 @@
+package reviewdog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestPrettyCommentWriter(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package a\n\nfunc f() {\n\tx := 1 // 𐐀\n\treturn\n}\n",
+		"b.go": "package b\r\n\r\nvar b = 1\r\n",
+	}
+	comments := []*Comment{
+		{
+			ToolName: "golint",
+			Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "a.go", Range: &rdf.Range{
+					Start: &rdf.Position{Line: 5, Column: 2},
+					End:   &rdf.Position{Line: 5, Column: 8},
+				}},
+				Message:  "unnecessary return",
+				Severity: rdf.Severity_WARNING,
+				Suggestions: []*rdf.Suggestion{{
+					Range: &rdf.Range{Start: &rdf.Position{Line: 5}, End: &rdf.Position{Line: 5}},
+				}},
+			}},
+		},
+		{
+			ToolName: "vet",
+			Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "a.go", Range: &rdf.Range{
+					Start: &rdf.Position{Line: 4, Column: 2},
+					End:   &rdf.Position{Line: 4, Column: 3},
+				}},
+				Message:  "x declared but not used",
+				Severity: rdf.Severity_ERROR,
+				Code:     &rdf.Code{Value: "unused"},
+				Suggestions: []*rdf.Suggestion{{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 4, Column: 2},
+						End:   &rdf.Position{Line: 4, Column: 3},
+					},
+					Text: "_",
+				}},
+			}},
+		},
+		{
+			ToolName: "vet",
+			Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "a.go", Range: &rdf.Range{
+					Start: &rdf.Position{Line: 4, Column: 12},
+					End:   &rdf.Position{Line: 4, Column: 16},
+				}},
+				Message:  "comment",
+				Severity: rdf.Severity_INFO,
+			}},
+		},
+		{
+			ToolName: "golint",
+			Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "b.go", Range: &rdf.Range{
+					Start: &rdf.Position{Line: 3},
+				}},
+				Message: "exported var",
+			}},
+		},
+		{
+			ToolName: "golint",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "c.go", Range: &rdf.Range{
+						Start: &rdf.Position{Line: 10, Column: 5},
+						End:   &rdf.Position{Line: 11, Column: 3},
+					}},
+					Message:  "deleted file",
+					Severity: rdf.Severity_ERROR,
+				},
+				SourceLines: map[int]string{10: "func c() {", 11: "\t}"},
+			},
+		},
+		{
+			ToolName: "golint",
+			Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+				Message:  "no file",
+				Severity: rdf.Severity_WARNING,
+			}},
+		},
+	}
+	want := `warning: no file [golint]
+
+a.go
+a.go:4:2: error[unused]: x declared but not used [vet]
+  4 | 	x := 1 // 𐐀
+    | 	^
+    = suggestion:
+  4 - 	x := 1 // 𐐀
+    + 	_ := 1 // 𐐀
+
+a.go:4:12: info: comment [vet]
+  4 | 	x := 1 // 𐐀
+    | 	          ^
+
+a.go:5:2: warning: unnecessary return [golint]
+  5 | 	return
+    | 	^^^^^^
+    = suggestion:
+  5 - 	return
+
+b.go
+b.go:3: exported var [golint]
+  3 | var b = 1
+
+c.go
+c.go:10:5: error: deleted file [golint]
+  10 | func c() {
+     |     ^^^^^^
+  11 | 	}
+     | 	^
+
+6 results (2 errors, 2 warnings, 1 info)
+`
+
+	buf := new(bytes.Buffer)
+	w := NewPrettyCommentWriter(buf, []string{"golint", "vet"}, false)
+	w.readFile = func(path string) ([]byte, error) {
+		if f, ok := files[path]; ok {
+			return []byte(f), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	ctx := context.Background()
+	for _, c := range comments {
+		if err := w.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("results are written before all the runners are flushed:\n%s", buf.String())
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("result has diff:\n%s", diff)
+	}
+}
+
+func TestPrettyCommentWriter_color(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewPrettyCommentWriter(buf, nil, true)
+	c := &Comment{
+		ToolName: "tool",
+		Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+			Location: &rdf.Location{Path: "a.go"},
+			Message:  "message",
+			Severity: rdf.Severity_ERROR,
+		}},
+	}
+	ctx := context.Background()
+	if err := w.Post(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := "\x1b[1m\x1b[36ma.go\x1b[0m\n" +
+		"\x1b[1ma.go\x1b[0m: \x1b[1m\x1b[31merror\x1b[0m: message \x1b[1m[tool]\x1b[0m\n\n" +
+		"1 result (\x1b[1m\x1b[31m1 error\x1b[0m)\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("result has diff:\n%s", diff)
+	}
+}
@@ -207,12 +207,42 @@ func (ch *Checker) summary(checks []*filter.FilteredDiagnostic) string {
 			filteredFindings = append(filteredFindings, c)
 		}
 	}
+	lines = append(lines, summarySeverityTable(findings, filteredFindings)...)
 	lines = append(lines, ch.summaryFindings("Findings", findings)...)
 	lines = append(lines, ch.summaryFindings("Filtered Findings", filteredFindings)...)
 
 	return strings.Join(lines, "\n")
 }
 
+// summarySeverityTable returns a Markdown table of the number of findings per
+// severity. It returns nothing if there are no findings at all.
+func summarySeverityTable(findings, filteredFindings []*filter.FilteredDiagnostic) []string {
+	if len(findings)+len(filteredFindings) == 0 {
+		return nil
+	}
+	count := func(checks []*filter.FilteredDiagnostic) map[rdf.Severity]int {
+		m := make(map[rdf.Severity]int)
+		for _, c := range checks {
+			m[c.Diagnostic.GetSeverity()]++
+		}
+		return m
+	}
+	found, filtered := count(findings), count(filteredFindings)
+	lines := []string{
+		"",
+		"| Severity | Findings | Filtered Findings |",
+		"| -------- | -------- | ----------------- |",
+	}
+	for _, s := range []rdf.Severity{rdf.Severity_ERROR, rdf.Severity_WARNING, rdf.Severity_INFO, rdf.Severity_UNKNOWN_SEVERITY} {
+		if found[s]+filtered[s] == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("| %s | %d | %d |", s, found[s], filtered[s]))
+	}
+	lines = append(lines, "")
+	return lines
+}
+
 func (ch *Checker) summaryFindings(name string, checks []*filter.FilteredDiagnostic) []string {
 	var lines []string
 	lines = append(lines, "<details>")
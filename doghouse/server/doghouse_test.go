@@ -663,3 +663,29 @@ func TestCheck_fail_check_with_403(t *testing.T) {
 		t.Error("resp.CheckedResults should not be nil")
 	}
 }
+
+func TestSummarySeverityTable(t *testing.T) {
+	findings := []*filter.FilteredDiagnostic{
+		{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_ERROR}},
+		{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_ERROR}},
+		{Diagnostic: &rdf.Diagnostic{}},
+	}
+	filteredFindings := []*filter.FilteredDiagnostic{
+		{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_INFO}},
+	}
+	want := []string{
+		"",
+		"| Severity | Findings | Filtered Findings |",
+		"| -------- | -------- | ----------------- |",
+		"| ERROR | 2 | 0 |",
+		"| INFO | 0 | 1 |",
+		"| UNKNOWN_SEVERITY | 1 | 0 |",
+		"",
+	}
+	if diff := cmp.Diff(summarySeverityTable(findings, filteredFindings), want); diff != "" {
+		t.Errorf("summarySeverityTable() diff: (-got +want)\n%s", diff)
+	}
+	if got := summarySeverityTable(nil, nil); len(got) != 0 {
+		t.Errorf("summarySeverityTable(nil, nil) = %v, want empty", got)
+	}
+}
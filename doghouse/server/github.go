@@ -7,6 +7,8 @@ import (
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v39/github"
+
+	"github.com/reviewdog/reviewdog/service/github/githubutils"
 )
 
 type NewGitHubClientOption struct {
@@ -33,7 +35,7 @@ func NewGitHubClient(ctx context.Context, opt *NewGitHubClientOption) (*github.C
 		return nil, fmt.Errorf("failed to create gh transport: %w", err)
 	}
 
-	client.Transport = itr
+	client.Transport = githubutils.NewRetryTransport(itr)
 	return github.NewClient(client), nil
 }
 
@@ -78,6 +78,11 @@ type DiffFilter struct {
 
 	difflines difflines
 	difffiles difffiles
+
+	// Same as difflines and difffiles, but indexed by old path and old line
+	// number to filter diagnostics which target the old file.
+	oldDifflines difflines
+	oldDifffiles difffiles
 }
 
 // difflines is a hash table of normalizedPath to line number to *diff.Line.
@@ -89,11 +94,13 @@ type difffiles map[normalizedPath]*diff.FileDiff
 // NewDiffFilter creates a new DiffFilter.
 func NewDiffFilter(diff []*diff.FileDiff, strip int, cwd string, mode Mode) *DiffFilter {
 	df := &DiffFilter{
-		strip:     strip,
-		cwd:       cwd,
-		mode:      mode,
-		difflines: make(difflines),
-		difffiles: make(difffiles),
+		strip:        strip,
+		cwd:          cwd,
+		mode:         mode,
+		difflines:    make(difflines),
+		difffiles:    make(difffiles),
+		oldDifflines: make(difflines),
+		oldDifffiles: make(difffiles),
 	}
 	// If cwd is empty, projectRelPath should not have any meaningful data too.
 	if cwd != "" {
@@ -119,6 +126,24 @@ func (df *DiffFilter) addDiff(filediffs []*diff.FileDiff) {
 			}
 		}
 		df.difflines[path] = lines
+
+		oldPath := df.normalizeOldDiffPath(filediff)
+		if oldPath.p == "" {
+			continue
+		}
+		df.oldDifffiles[oldPath] = filediff
+		oldLines, ok := df.oldDifflines[oldPath]
+		if !ok {
+			oldLines = make(map[int]*diff.Line)
+		}
+		for _, hunk := range filediff.Hunks {
+			for _, line := range hunk.Lines {
+				if line.LnumOld > 0 {
+					oldLines[line.LnumOld] = line
+				}
+			}
+		}
+		df.oldDifflines[oldPath] = oldLines
 	}
 }
 
@@ -138,6 +163,23 @@ func (df *DiffFilter) ShouldReport(path string, lnum int) (bool, *diff.FileDiff,
 	return df.isSignificantLine(line), file, line
 }
 
+// ShouldReportOld is same as ShouldReport, but the given path and lnum are
+// interpreted as the old path and the old line number. In ModeAdded, only
+// deleted lines are significant.
+func (df *DiffFilter) ShouldReportOld(path string, lnum int) (bool, *diff.FileDiff, *diff.Line) {
+	npath := df.normalizePath(path)
+	file := df.oldDifffiles[npath]
+	lines, ok := df.oldDifflines[npath]
+	if !ok {
+		return df.mode == ModeNoFilter, file, nil
+	}
+	line, ok := lines[lnum]
+	if !ok {
+		return df.mode == ModeNoFilter || df.mode == ModeFile, file, nil
+	}
+	return df.isSignificantOldLine(line), file, line
+}
+
 // DiffLine returns diff data from given new path and lnum. Returns nil if not
 // found.
 func (df *DiffFilter) DiffLine(path string, lnum int) *diff.Line {
@@ -153,6 +195,16 @@ func (df *DiffFilter) DiffLine(path string, lnum int) *diff.Line {
 	return line
 }
 
+// DiffLineOld returns diff data from given old path and lnum. Returns nil if
+// not found.
+func (df *DiffFilter) DiffLineOld(path string, lnum int) *diff.Line {
+	lines, ok := df.oldDifflines[df.normalizePath(path)]
+	if !ok {
+		return nil
+	}
+	return lines[lnum]
+}
+
 func (df *DiffFilter) isSignificantLine(line *diff.Line) bool {
 	switch df.mode {
 	case ModeDiffContext, ModeFile, ModeNoFilter:
@@ -163,6 +215,16 @@ func (df *DiffFilter) isSignificantLine(line *diff.Line) bool {
 	return false
 }
 
+func (df *DiffFilter) isSignificantOldLine(line *diff.Line) bool {
+	switch df.mode {
+	case ModeDiffContext, ModeFile, ModeNoFilter:
+		return true // any lines in diff are significant.
+	case ModeAdded, ModeDefault:
+		return line.Type == diff.LineDeleted
+	}
+	return false
+}
+
 // normalizedPath is file path which is relative to **project root dir** or
 // to current dir if project root not found.
 type normalizedPath struct{ p string }
@@ -194,6 +256,10 @@ func (df *DiffFilter) normalizeDiffPath(filediff *diff.FileDiff) normalizedPath
 	return normalizedPath{p: NormalizeDiffPath(filediff.PathNew, df.strip)}
 }
 
+func (df *DiffFilter) normalizeOldDiffPath(filediff *diff.FileDiff) normalizedPath {
+	return normalizedPath{p: NormalizeDiffPath(filediff.PathOld, df.strip)}
+}
+
 // NormalizeDiffPath return path normalized path from given path in diff with
 // strip.
 func NormalizeDiffPath(diffpath string, strip int) string {
@@ -29,8 +29,17 @@ type FilteredDiagnostic struct {
 	// Optional. Currently available only when it's in diff context.
 	SourceLines map[int]string
 
+	// Position of the diagnostic in the old file. If the diagnostic targets the
+	// old file (rdf.Location.Old), they are same as the diagnostic's path and
+	// start line, and SourceLines are keyed by old line numbers.
 	OldPath string
 	OldLine int
+	// OldEndLine is the line in the old file of the diagnostic's end line.
+	OldEndLine int
+	// OldLines maps lines in SourceLines to lines in the old file. Lines
+	// without a line in the old file (i.e. added lines) are mapped to 0. It's
+	// nil if the diagnostic targets the old file.
+	OldLines map[int]int
 }
 
 // FilterCheck filters check results by diff. It doesn't drop check which
@@ -49,6 +58,11 @@ func FilterCheck(results []*rdf.Diagnostic, diff []*diff.FileDiff, strip int,
 			endLine = startLine
 		}
 		check.InDiffContext = true
+		if loc.GetOld() {
+			filterOldLocation(check, df, loc.GetPath(), startLine, endLine)
+			checks = append(checks, check)
+			continue
+		}
 		for l := startLine; l <= endLine; l++ {
 			shouldReport, difffile, diffline := df.ShouldReport(loc.GetPath(), l)
 			check.ShouldReport = check.ShouldReport || shouldReport
@@ -56,13 +70,16 @@ func FilterCheck(results []*rdf.Diagnostic, diff []*diff.FileDiff, strip int,
 			check.InDiffContext = check.InDiffContext && diffline != nil
 			if diffline != nil {
 				check.SourceLines[l] = diffline.Content
+				check.addOldLine(l, diffline)
 			}
 			if difffile != nil {
 				check.InDiffFile = true
 				if l == startLine {
-					// TODO(haya14busa): Support endline as well especially for GitLab.
 					check.OldPath, check.OldLine = getOldPosition(difffile, strip, loc.GetPath(), l)
 				}
+				if l == endLine {
+					_, check.OldEndLine = getOldPosition(difffile, strip, loc.GetPath(), l)
+				}
 			}
 		}
 		// Add source lines for suggestions.
@@ -73,6 +90,7 @@ func FilterCheck(results []*rdf.Diagnostic, diff []*diff.FileDiff, strip int,
 			for l := start; l <= end; l++ {
 				if diffline := df.DiffLine(loc.GetPath(), l); diffline != nil {
 					check.SourceLines[l] = diffline.Content
+					check.addOldLine(l, diffline)
 				} else {
 					inDiffContext = false
 				}
@@ -86,6 +104,37 @@ func FilterCheck(results []*rdf.Diagnostic, diff []*diff.FileDiff, strip int,
 	return checks
 }
 
+func (check *FilteredDiagnostic) addOldLine(lnum int, line *diff.Line) {
+	if check.OldLines == nil {
+		check.OldLines = make(map[int]int)
+	}
+	check.OldLines[lnum] = line.LnumOld
+}
+
+// filterOldLocation fills in filtering info of a diagnostic whose location
+// targets the old file. Suggestions are ignored because they cannot be applied
+// to the old file.
+func filterOldLocation(check *FilteredDiagnostic, df *DiffFilter, path string, startLine, endLine int) {
+	for l := startLine; l <= endLine; l++ {
+		shouldReport, difffile, diffline := df.ShouldReportOld(path, l)
+		check.ShouldReport = check.ShouldReport || shouldReport
+		// all lines must be in diff.
+		check.InDiffContext = check.InDiffContext && diffline != nil
+		if diffline != nil {
+			check.SourceLines[l] = diffline.Content
+		}
+		if difffile != nil {
+			check.InDiffFile = true
+			if l == startLine {
+				check.OldPath, check.OldLine = path, l
+			}
+			if l == endLine {
+				check.OldEndLine = l
+			}
+		}
+	}
+}
+
 // NormalizePath return normalized path with workdir and relative path to
 // project.
 func NormalizePath(path, workdir, projectRelPath string) string {
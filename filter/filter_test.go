@@ -92,8 +92,10 @@ func TestFilterCheckByAddedLines(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{1: "unchanged, contextual line"},
+			OldLines:      map[int]int{1: 1},
 			OldPath:       "sample.old.txt",
 			OldLine:       1,
+			OldEndLine:    1,
 		},
 		{
 			Diagnostic: &rdf.Diagnostic{
@@ -106,6 +108,7 @@ func TestFilterCheckByAddedLines(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{2: "added line"},
+			OldLines:      map[int]int{2: 0},
 			OldPath:       "sample.old.txt",
 			OldLine:       0,
 		},
@@ -120,8 +123,10 @@ func TestFilterCheckByAddedLines(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{1: `" vim: nofixeol noendofline`},
+			OldLines:      map[int]int{1: 1},
 			OldPath:       "nonewline.old.txt",
 			OldLine:       1,
+			OldEndLine:    1,
 		},
 		{
 			Diagnostic: &rdf.Diagnostic{
@@ -134,6 +139,7 @@ func TestFilterCheckByAddedLines(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{3: "b"},
+			OldLines:      map[int]int{3: 0},
 			OldPath:       "nonewline.old.txt",
 			OldLine:       0,
 		},
@@ -152,6 +158,7 @@ func TestFilterCheckByAddedLines(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{1: "unchanged, contextual line", 2: "added line"},
+			OldLines:      map[int]int{1: 1, 2: 0},
 			OldPath:       "sample.old.txt",
 			OldLine:       1,
 		},
@@ -211,8 +218,10 @@ func TestFilterCheckByDiffContext(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{1: "unchanged, contextual line"},
+			OldLines:      map[int]int{1: 1},
 			OldPath:       "sample.old.txt",
 			OldLine:       1,
+			OldEndLine:    1,
 		},
 		{
 			Diagnostic: &rdf.Diagnostic{
@@ -225,6 +234,7 @@ func TestFilterCheckByDiffContext(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{2: "added line"},
+			OldLines:      map[int]int{2: 0},
 			OldPath:       "sample.old.txt",
 			OldLine:       0,
 		},
@@ -239,6 +249,7 @@ func TestFilterCheckByDiffContext(t *testing.T) {
 			InDiffFile:    true,
 			InDiffContext: true,
 			SourceLines:   map[int]string{3: "added line"},
+			OldLines:      map[int]int{3: 0},
 			OldPath:       "sample.old.txt",
 			OldLine:       0,
 		},
@@ -266,8 +277,9 @@ func TestFilterCheckByDiffContext(t *testing.T) {
 				3: "added line",
 				4: "unchanged, contextual line",
 			},
-			OldPath: "sample.old.txt",
-			OldLine: 0,
+			OldLines: map[int]int{2: 0, 3: 0, 4: 3},
+			OldPath:  "sample.old.txt",
+			OldLine:  0,
 		},
 	}
 	filediffs, _ := diff.ParseMultiFile(strings.NewReader(diffContent))
@@ -277,6 +289,111 @@ func TestFilterCheckByDiffContext(t *testing.T) {
 	}
 }
 
+func TestFilterCheckOldLocation(t *testing.T) {
+	results := []*rdf.Diagnostic{
+		{
+			Location: &rdf.Location{
+				Path:  "sample.old.txt",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				Old:   true,
+			},
+		},
+		{
+			Location: &rdf.Location{
+				Path:  "sample.old.txt",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+				Old:   true,
+			},
+		},
+		{
+			Message: "not in diff",
+			Location: &rdf.Location{
+				Path:  "sample.old.txt",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				Old:   true,
+			},
+		},
+		{
+			Message: "new path is not old path",
+			Location: &rdf.Location{
+				Path:  "sample.new.txt",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+				Old:   true,
+			},
+		},
+	}
+	want := []*FilteredDiagnostic{
+		{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "sample.old.txt",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+					Old:   true,
+				},
+			},
+			ShouldReport:  false,
+			InDiffFile:    true,
+			InDiffContext: true,
+			SourceLines:   map[int]string{1: "unchanged, contextual line"},
+			OldPath:       "sample.old.txt",
+			OldLine:       1,
+			OldEndLine:    1,
+		},
+		{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "sample.old.txt",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+					Old:   true,
+				},
+			},
+			ShouldReport:  true,
+			InDiffFile:    true,
+			InDiffContext: true,
+			SourceLines:   map[int]string{2: "deleted line"},
+			OldPath:       "sample.old.txt",
+			OldLine:       2,
+			OldEndLine:    2,
+		},
+		{
+			Diagnostic: &rdf.Diagnostic{
+				Message: "not in diff",
+				Location: &rdf.Location{
+					Path:  "sample.old.txt",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					Old:   true,
+				},
+			},
+			ShouldReport:  false,
+			InDiffFile:    true,
+			InDiffContext: false,
+			SourceLines:   map[int]string{},
+			OldPath:       "sample.old.txt",
+			OldLine:       14,
+			OldEndLine:    14,
+		},
+		{
+			Diagnostic: &rdf.Diagnostic{
+				Message: "new path is not old path",
+				Location: &rdf.Location{
+					Path:  "sample.new.txt",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+					Old:   true,
+				},
+			},
+			ShouldReport:  false,
+			InDiffFile:    false,
+			InDiffContext: false,
+			SourceLines:   map[int]string{},
+		},
+	}
+	filediffs, _ := diff.ParseMultiFile(strings.NewReader(diffContent))
+	got := FilterCheck(results, filediffs, 0, "", ModeAdded)
+	if value := cmp.Diff(got, want, protocmp.Transform()); value != "" {
+		t.Error(value)
+	}
+}
+
 func findFileDiff(filediffs []*diff.FileDiff, path string, strip int) *diff.FileDiff {
 	for _, file := range filediffs {
 		if NormalizeDiffPath(file.PathNew, strip) == path {
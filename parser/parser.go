@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/reviewdog/errorformat/fmts"
 
@@ -43,6 +44,10 @@ func New(opt *Option) (Parser, error) {
 		return NewDiffParser(opt.DiffStrip), nil
 	}
 
+	if strings.HasPrefix(name, PluginPrefix) {
+		return NewPluginParser(strings.TrimPrefix(name, PluginPrefix))
+	}
+
 	// use defined errorformat
 	if name != "" {
 		efm, ok := fmts.DefinedFmts()[name]
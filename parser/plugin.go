@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &PluginParser{}
+
+// PluginPrefix is the prefix of format names of parser plugins.
+const PluginPrefix = "plugin:"
+
+// PluginExecutablePrefix is the prefix of parser plugin executables.
+const PluginExecutablePrefix = "reviewdog-parser-"
+
+// maxPluginLineSize is the maximum size of a line of rdjsonl from parser plugins.
+const maxPluginLineSize = 16 * 1024 * 1024
+
+// PluginParser is parser which pipes tool output through a parser plugin
+// executable, reviewdog-parser-<name> in $PATH. The plugin reads the output
+// from stdin and writes rdjsonl to stdout.
+type PluginParser struct {
+	name string
+	path string
+}
+
+// NewPluginParser returns a new PluginParser for the plugin of the name.
+func NewPluginParser(name string) (*PluginParser, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid parser plugin name: %q", name)
+	}
+	path, err := exec.LookPath(PluginExecutablePrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("parser plugin %q is not found: %w", name, err)
+	}
+	return &PluginParser{name: name, path: path}, nil
+}
+
+// Parse runs the plugin with r as stdin and parses its stdout as rdjsonl.
+// It fails if the plugin exits with non-zero code or emits invalid rdjsonl.
+func (p *PluginParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.path)
+	cmd.Stdin = r
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("parser plugin %q failed: %w: %s", p.name, err, msg)
+		}
+		return nil, fmt.Errorf("parser plugin %q failed: %w", p.name, err)
+	}
+
+	var results []*rdf.Diagnostic
+	s := bufio.NewScanner(&stdout)
+	s.Buffer(nil, maxPluginLineSize)
+	for lnum := 1; s.Scan(); lnum++ {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		d := new(rdf.Diagnostic)
+		if err := protojson.Unmarshal(line, d); err != nil {
+			return nil, fmt.Errorf("parser plugin %q emitted invalid rdjsonl at line %d: %w: %s",
+				p.name, lnum, err, truncate(string(line), 100))
+		}
+		if d.GetOriginalOutput() == "" {
+			d.OriginalOutput = string(line)
+		}
+		results = append(results, d)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output of parser plugin %q: %w", p.name, err)
+	}
+	return results, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupPluginParser installs a parser plugin script to a temporary $PATH.
+func setupPluginParser(t *testing.T, name, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, PluginExecutablePrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestPluginParser(t *testing.T) {
+	// The plugin converts "path:line:message" to rdjsonl.
+	setupPluginParser(t, "colon", `
+while IFS=: read -r path line msg; do
+  printf '{"message":"%s","location":{"path":"%s","range":{"start":{"line":%s}}}}\n\n' "$msg" "$path" "$line"
+done
+`)
+	p, err := New(&Option{FormatName: "plugin:colon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostics, err := p.Parse(strings.NewReader("a.go:14:msg1\nb.go:1:msg2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 2 {
+		t.Fatalf("got %d diagnostics, want 2", len(diagnostics))
+	}
+	for i, want := range []struct {
+		path string
+		line int32
+		msg  string
+	}{{"a.go", 14, "msg1"}, {"b.go", 1, "msg2"}} {
+		d := diagnostics[i]
+		if d.GetLocation().GetPath() != want.path || d.GetLocation().GetRange().GetStart().GetLine() != want.line || d.GetMessage() != want.msg {
+			t.Errorf("%d: got %v, want %+v", i, d, want)
+		}
+		if d.GetOriginalOutput() == "" {
+			t.Errorf("%d: original output is empty", i)
+		}
+	}
+}
+
+func TestPluginParser_errors(t *testing.T) {
+	setupPluginParser(t, "invalid", `echo '{"message":"ok"}'; echo 'not json'`)
+	setupPluginParser(t, "fail", `echo 'unsupported input' >&2; exit 2`)
+
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{name: "invalid", wantErr: `parser plugin "invalid" emitted invalid rdjsonl at line 2`},
+		{name: "fail", wantErr: `parser plugin "fail" failed: exit status 2: unsupported input`},
+	}
+	for _, tt := range tests {
+		p, err := NewPluginParser(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = p.Parse(strings.NewReader(""))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	for _, name := range []string{"", "../invalid", "notfound"} {
+		if _, err := New(&Option{FormatName: PluginPrefix + name}); err == nil {
+			t.Errorf("New(plugin:%s) should fail", name)
+		}
+	}
+}
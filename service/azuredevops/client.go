@@ -0,0 +1,212 @@
+package azuredevops
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiVersion = "7.0"
+
+// Thread statuses of pull request threads.
+const (
+	ThreadStatusActive  = "active"
+	ThreadStatusPending = "pending"
+	ThreadStatusFixed   = "fixed"
+	ThreadStatusClosed  = "closed"
+)
+
+// Client is a client for Azure DevOps Git pull request API.
+//
+// API:
+//  https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-threads
+type Client struct {
+	cli           *http.Client
+	baseURL       string
+	authorization string
+}
+
+// PullRequest is a pull request of Azure Repos.
+type PullRequest struct {
+	PullRequestID         int        `json:"pullRequestId"`
+	LastMergeSourceCommit *GitCommit `json:"lastMergeSourceCommit"`
+	LastMergeTargetCommit *GitCommit `json:"lastMergeTargetCommit"`
+}
+
+// GitCommit is a reference to a commit.
+type GitCommit struct {
+	CommitID string `json:"commitId"`
+}
+
+// Thread is a comment thread of pull request.
+type Thread struct {
+	ID            int                  `json:"id,omitempty"`
+	Comments      []*Comment           `json:"comments,omitempty"`
+	Status        string               `json:"status,omitempty"`
+	ThreadContext *ThreadContext       `json:"threadContext,omitempty"`
+	Properties    map[string]*Property `json:"properties,omitempty"`
+	IsDeleted     bool                 `json:"isDeleted,omitempty"`
+}
+
+// Comment is a comment in thread.
+type Comment struct {
+	ParentCommentID int    `json:"parentCommentId"`
+	Content         string `json:"content"`
+	CommentType     string `json:"commentType,omitempty"`
+	IsDeleted       bool   `json:"isDeleted,omitempty"`
+}
+
+// ThreadContext is the location of thread in the files of pull request.
+// Right file is the file in the source branch and left file is the file in
+// the target branch.
+type ThreadContext struct {
+	FilePath       string        `json:"filePath"`
+	RightFileStart *FilePosition `json:"rightFileStart,omitempty"`
+	RightFileEnd   *FilePosition `json:"rightFileEnd,omitempty"`
+	LeftFileStart  *FilePosition `json:"leftFileStart,omitempty"`
+	LeftFileEnd    *FilePosition `json:"leftFileEnd,omitempty"`
+}
+
+// FilePosition is a position in file. Both line and offset are 1-based.
+type FilePosition struct {
+	Line   int `json:"line"`
+	Offset int `json:"offset"`
+}
+
+// Property is a typed property of thread.
+type Property struct {
+	Type  string `json:"$type"`
+	Value string `json:"$value"`
+}
+
+// NewStringProperty returns a property of string value.
+func NewStringProperty(v string) *Property {
+	return &Property{Type: "System.String", Value: v}
+}
+
+// UnexpectedResponseError is returned when Azure DevOps API returns unexpected status code.
+type UnexpectedResponseError struct {
+	Code int
+	Body []byte
+}
+
+func (e UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("azure devops api: unexpected response code %d: %s", e.Code, e.Body)
+}
+
+// NewClient returns a new Client for the repository of the project in the
+// collection (organization), e.g. https://dev.azure.com/{organization}/.
+// authorization is a value of Authorization header built by BasicAuth or
+// BearerAuth.
+func NewClient(cli *http.Client, collectionURI, project, repo, authorization string) *Client {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	return &Client{
+		cli: cli,
+		baseURL: fmt.Sprintf("%s/%s/_apis/git/repositories/%s",
+			strings.TrimSuffix(collectionURI, "/"), url.PathEscape(project), url.PathEscape(repo)),
+		authorization: authorization,
+	}
+}
+
+// BasicAuth returns an Authorization header value for personal access token.
+func BasicAuth(pat string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+pat))
+}
+
+// BearerAuth returns an Authorization header value for OAuth token such as
+// System.AccessToken of Azure Pipelines.
+func BearerAuth(token string) string {
+	return "Bearer " + token
+}
+
+// GetPullRequest returns the pull request.
+func (c *Client) GetPullRequest(ctx context.Context, pr int) (*PullRequest, error) {
+	var p PullRequest
+	if err := c.do(ctx, http.MethodGet, c.pullRequestURL(pr, ""), nil, &p); err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	return &p, nil
+}
+
+// ListPullRequestThreads returns all threads of the pull request.
+func (c *Client) ListPullRequestThreads(ctx context.Context, pr int) ([]*Thread, error) {
+	var resp struct {
+		Value []*Thread `json:"value"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.pullRequestURL(pr, "/threads"), nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list pull request threads: %w", err)
+	}
+	return resp.Value, nil
+}
+
+// CreatePullRequestThread creates a thread on the pull request.
+func (c *Client) CreatePullRequestThread(ctx context.Context, pr int, thread *Thread) error {
+	if err := c.do(ctx, http.MethodPost, c.pullRequestURL(pr, "/threads"), thread, nil); err != nil {
+		return fmt.Errorf("failed to create pull request thread: %w", err)
+	}
+	return nil
+}
+
+// UpdatePullRequestThreadStatus updates status of the thread.
+func (c *Client) UpdatePullRequestThreadStatus(ctx context.Context, pr, threadID int, status string) error {
+	u := c.pullRequestURL(pr, fmt.Sprintf("/threads/%d", threadID))
+	if err := c.do(ctx, http.MethodPatch, u, &Thread{Status: status}, nil); err != nil {
+		return fmt.Errorf("failed to update pull request thread: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) pullRequestURL(pr int, path string) string {
+	return fmt.Sprintf("%s/pullRequests/%d%s?api-version=%s", c.baseURL, pr, path, apiVersion)
+}
+
+// do sends a request with JSON encoded body if it's not nil, and decodes the
+// response into out if it's not nil.
+func (c *Client) do(ctx context.Context, method, u string, body, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return UnexpectedResponseError{Code: resp.StatusCode, Body: b}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
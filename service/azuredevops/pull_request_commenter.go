@@ -0,0 +1,259 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.CommentService = &PullRequestCommenter{}
+var _ reviewdog.BulkCommentService = &PullRequestCommenter{}
+
+// Properties of threads posted by reviewdog.
+const (
+	toolProperty        = "reviewdog.tool"
+	fingerprintProperty = "reviewdog.fingerprint"
+)
+
+// PullRequestCommenter is a comment service for Azure Repos pull requests.
+//
+// API:
+//  https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-threads/create
+//  POST {organization}/{project}/_apis/git/repositories/{repositoryId}/pullRequests/{pullRequestId}/threads
+type PullRequestCommenter struct {
+	cli *Client
+	pr  int
+
+	muComments   sync.Mutex
+	postComments []*reviewdog.Comment
+	// reported holds fingerprints of all the results reported so far.
+	reported map[string]bool
+
+	// status of new threads.
+	status string
+	// runners whose stale threads are closed as fixed after all of them are
+	// flushed.
+	runners *commentutil.Runners
+
+	// wd is working directory relative to root of repository.
+	wd string
+}
+
+// NewPullRequestCommenter returns a new PullRequestCommenter service.
+// PullRequestCommenter service needs git command in $PATH.
+//
+// New threads have the given status ("active" if empty). Threads posted by
+// reviewdog for the given runners are set to "fixed" when their results are
+// no longer reported, and set to the status again when they are reported again.
+func NewPullRequestCommenter(cli *Client, pr int, runners []string, status string) (*PullRequestCommenter, error) {
+	workDir, err := serviceutil.GitRelWorkdir()
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestCommenter needs 'git' command: %w", err)
+	}
+	if status == "" {
+		status = ThreadStatusActive
+	}
+	return &PullRequestCommenter{
+		cli:      cli,
+		pr:       pr,
+		reported: make(map[string]bool),
+		status:   status,
+		runners:  commentutil.NewRunners(runners),
+		wd:       workDir,
+	}, nil
+}
+
+// Post accepts a comment and holds it. Flush method actually posts comments to
+// Azure DevOps.
+func (p *PullRequestCommenter) Post(_ context.Context, c *reviewdog.Comment) error {
+	c.Result.Diagnostic.GetLocation().Path = filepath.ToSlash(
+		filepath.Join(p.wd, c.Result.Diagnostic.GetLocation().GetPath()))
+	p.muComments.Lock()
+	defer p.muComments.Unlock()
+	p.postComments = append(p.postComments, c)
+	return nil
+}
+
+// Flush posts comments which has not been posted yet as threads. Once all the
+// runners are flushed, it updates statuses of stale threads as well.
+func (p *PullRequestCommenter) Flush(ctx context.Context) error {
+	p.muComments.Lock()
+	defer p.muComments.Unlock()
+
+	threads, err := p.cli.ListPullRequestThreads(ctx, p.pr)
+	if err != nil {
+		return err
+	}
+	posted := make(map[string]bool)
+	for _, t := range threads {
+		if fp := threadFingerprint(t); fp != "" {
+			posted[fp] = true
+		}
+	}
+
+	fps := fingerprints(p.postComments)
+	for i, c := range p.postComments {
+		fp := fps[i]
+		p.reported[fp] = true
+		if posted[fp] {
+			continue
+		}
+		posted[fp] = true
+		if err := p.cli.CreatePullRequestThread(ctx, p.pr, p.buildThread(c, fp)); err != nil {
+			return err
+		}
+	}
+	p.postComments = p.postComments[:0]
+
+	if !p.runners.Flush() || p.runners.Len() == 0 {
+		return nil
+	}
+	return p.updateThreadStatuses(ctx, threads)
+}
+
+// updateThreadStatuses sets threads posted by reviewdog for the runners to
+// "fixed" if their results are no longer reported, and reactivates fixed ones
+// whose results are reported again. Threads closed by users are left as is.
+func (p *PullRequestCommenter) updateThreadStatuses(ctx context.Context, threads []*Thread) error {
+	for _, t := range threads {
+		fp := threadFingerprint(t)
+		if fp == "" || !p.runners.Has(t.Properties[toolProperty].Value) {
+			continue
+		}
+		var status string
+		switch {
+		case !p.reported[fp] && (t.Status == ThreadStatusActive || t.Status == ThreadStatusPending):
+			status = ThreadStatusFixed
+		case p.reported[fp] && t.Status == ThreadStatusFixed:
+			status = p.status
+		default:
+			continue
+		}
+		if err := p.cli.UpdatePullRequestThreadStatus(ctx, p.pr, t.ID, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fingerprints returns fingerprints of the comments. Results with the same
+// content (e.g. the same message on different lines) are numbered in order of
+// their positions, so that each of them has its own thread. Results at the same
+// position get the same fingerprint, so that they are posted once.
+func fingerprints(comments []*reviewdog.Comment) []string {
+	groups := make(map[string][]int)
+	for i, c := range comments {
+		fp := commentutil.Fingerprint(c)
+		groups[fp] = append(groups[fp], i)
+	}
+	fps := make([]string, len(comments))
+	for fp, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return lessPosition(comments[group[i]], comments[group[j]])
+		})
+		n := 0
+		for i, idx := range group {
+			if i > 0 && lessPosition(comments[group[i-1]], comments[idx]) {
+				n++
+			}
+			fps[idx] = fmt.Sprintf("%s-%d", fp, n)
+		}
+	}
+	return fps
+}
+
+func lessPosition(a, b *reviewdog.Comment) bool {
+	pa := a.Result.Diagnostic.GetLocation().GetRange().GetStart()
+	pb := b.Result.Diagnostic.GetLocation().GetRange().GetStart()
+	if pa.GetLine() != pb.GetLine() {
+		return pa.GetLine() < pb.GetLine()
+	}
+	return pa.GetColumn() < pb.GetColumn()
+}
+
+// threadFingerprint returns the fingerprint of the thread posted by reviewdog,
+// or empty string for other threads.
+func threadFingerprint(t *Thread) string {
+	if t.IsDeleted || t.Properties[toolProperty] == nil || t.Properties[fingerprintProperty] == nil {
+		return ""
+	}
+	return t.Properties[fingerprintProperty].Value
+}
+
+// buildThread returns a thread on the file if the result is in diff file,
+// otherwise a general thread with its location.
+func (p *PullRequestCommenter) buildThread(c *reviewdog.Comment, fp string) *Thread {
+	body := commentutil.MarkdownComment(c)
+	threadContext := buildThreadContext(c)
+	if threadContext == nil {
+		body = generalCommentBody(c, body)
+	}
+	return &Thread{
+		Comments: []*Comment{{
+			ParentCommentID: 0,
+			Content:         body,
+			CommentType:     "text",
+		}},
+		Status:        p.status,
+		ThreadContext: threadContext,
+		Properties: map[string]*Property{
+			toolProperty:        NewStringProperty(c.ToolName),
+			fingerprintProperty: NewStringProperty(fp),
+		},
+	}
+}
+
+// buildThreadContext returns the range of the result in the right file, or in
+// the left file if the result targets the old file. It returns nil if the
+// result isn't in diff file or doesn't have line number.
+func buildThreadContext(c *reviewdog.Comment) *ThreadContext {
+	loc := c.Result.Diagnostic.GetLocation()
+	start := int(loc.GetRange().GetStart().GetLine())
+	if !c.Result.InDiffFile || start == 0 {
+		return nil
+	}
+	end := int(loc.GetRange().GetEnd().GetLine())
+	if end < start {
+		end = start
+	}
+	startOffset := int(loc.GetRange().GetStart().GetColumn())
+	if startOffset == 0 {
+		startOffset = 1
+	}
+	endOffset := int(loc.GetRange().GetEnd().GetColumn())
+	if endOffset == 0 {
+		// Highlight the whole end line if its content is available.
+		endOffset = len(c.Result.SourceLines[end]) + 1
+	}
+
+	tc := &ThreadContext{FilePath: "/" + loc.GetPath()}
+	startPos := &FilePosition{Line: start, Offset: startOffset}
+	endPos := &FilePosition{Line: end, Offset: endOffset}
+	if loc.GetOld() {
+		tc.LeftFileStart, tc.LeftFileEnd = startPos, endPos
+	} else {
+		tc.RightFileStart, tc.RightFileEnd = startPos, endPos
+	}
+	return tc
+}
+
+// generalCommentBody returns a body of general thread, which has the location
+// of the result as well.
+func generalCommentBody(c *reviewdog.Comment, body string) string {
+	loc := c.Result.Diagnostic.GetLocation()
+	if loc.GetPath() == "" {
+		return body
+	}
+	location := loc.GetPath()
+	if lnum := loc.GetRange().GetStart().GetLine(); lnum > 0 {
+		location = fmt.Sprintf("%s:%d", location, lnum)
+	}
+	return fmt.Sprintf("%s\n\n`%s`", body, location)
+}
+
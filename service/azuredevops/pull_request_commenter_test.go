@@ -0,0 +1,207 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+func TestPullRequestCommenter_Post_Flush(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(tool, path string, line, endLine int32, old bool, msg string, inDiff bool) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: tool,
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: path,
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: line},
+							End:   &rdf.Position{Line: endLine},
+						},
+						Old: old,
+					},
+					Message: msg,
+				},
+				InDiffFile:  inDiff,
+				SourceLines: map[int]string{int(endLine): "abc"},
+			},
+		}
+	}
+	alreadyPosted := newComment("tool1", "file.go", 1, 1, false, "already posted", true)
+	reported := newComment("tool1", "file.go", 5, 5, false, "reported again", true)
+	inline := newComment("tool1", "file.go", 14, 15, false, "new thread", true)
+	old := newComment("tool1", "file.go", 3, 3, true, "removed line", true)
+	// the same result as alreadyPosted on another line
+	duplicate := newComment("tool1", "file.go", 40, 40, false, "already posted", true)
+	general := newComment("tool2", "other.go", 10, 10, false, "not in diff", false)
+	comments := []*reviewdog.Comment{alreadyPosted, reported, inline, old, duplicate, general}
+
+	// fingerprint of the n-th result with the same content
+	fingerprint := func(c *reviewdog.Comment, n int) string {
+		return commentutil.Fingerprint(c) + "-" + strconv.Itoa(n)
+	}
+	props := func(tool string, c *reviewdog.Comment) map[string]*Property {
+		return map[string]*Property{
+			toolProperty:        NewStringProperty(tool),
+			fingerprintProperty: NewStringProperty(fingerprint(c, 0)),
+		}
+	}
+	existing := []*Thread{
+		{ID: 1, Status: ThreadStatusActive, Properties: props("tool1", alreadyPosted)},
+		{ID: 2, Status: ThreadStatusFixed, Properties: props("tool1", reported)},
+		{ID: 3, Status: ThreadStatusActive, Properties: map[string]*Property{
+			toolProperty:        NewStringProperty("tool1"),
+			fingerprintProperty: NewStringProperty("stale"),
+		}},
+		{ID: 4, Status: ThreadStatusActive, Properties: map[string]*Property{
+			toolProperty:        NewStringProperty("tool3"),
+			fingerprintProperty: NewStringProperty("other runner"),
+		}},
+		{ID: 5, Status: ThreadStatusClosed, Properties: map[string]*Property{
+			toolProperty:        NewStringProperty("tool1"),
+			fingerprintProperty: NewStringProperty("closed by user"),
+		}},
+		{ID: 6, Status: ThreadStatusActive, Comments: []*Comment{{Content: "human"}}},
+	}
+
+	var created []*Thread
+	updated := make(map[int]string)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/project/_apis/git/repositories/repo/pullRequests/14/threads", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), BasicAuth("pat"); got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": existing, "count": len(existing)})
+		case http.MethodPost:
+			var th Thread
+			if err := json.NewDecoder(r.Body).Decode(&th); err != nil {
+				t.Error(err)
+			}
+			created = append(created, &th)
+			json.NewEncoder(w).Encode(&th)
+		default:
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+	})
+	for _, id := range []string{"2", "3"} {
+		id := id
+		mux.HandleFunc("/org/project/_apis/git/repositories/repo/pullRequests/14/threads/"+id, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPatch {
+				t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+			}
+			var th Thread
+			if err := json.NewDecoder(r.Body).Decode(&th); err != nil {
+				t.Error(err)
+			}
+			n, _ := strconv.Atoi(id)
+			updated[n] = th.Status
+			w.Write([]byte(`{}`))
+		})
+	}
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli := NewClient(nil, ts.URL+"/org", "project", "repo", BasicAuth("pat"))
+	p, err := NewPullRequestCommenter(cli, 14, []string{"tool1", "tool2"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, c := range comments[:5] {
+		if err := p.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 0 {
+		t.Errorf("threads are updated before all the runners are flushed: %v", updated)
+	}
+	if err := p.Post(ctx, general); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(created) != 4 {
+		t.Fatalf("created %d threads, want 4", len(created))
+	}
+	wantContexts := []*ThreadContext{
+		{
+			FilePath:       "/file.go",
+			RightFileStart: &FilePosition{Line: 14, Offset: 1},
+			RightFileEnd:   &FilePosition{Line: 15, Offset: 4},
+		},
+		{
+			FilePath:      "/file.go",
+			LeftFileStart: &FilePosition{Line: 3, Offset: 1},
+			LeftFileEnd:   &FilePosition{Line: 3, Offset: 4},
+		},
+		{
+			FilePath:       "/file.go",
+			RightFileStart: &FilePosition{Line: 40, Offset: 1},
+			RightFileEnd:   &FilePosition{Line: 40, Offset: 4},
+		},
+		nil,
+	}
+	wantFingerprints := []string{fingerprint(inline, 0), fingerprint(old, 0), fingerprint(duplicate, 1), fingerprint(general, 0)}
+	for i, th := range created {
+		if diff := cmp.Diff(wantContexts[i], th.ThreadContext); diff != "" {
+			t.Errorf("thread %d context diff (-want +got):\n%s", i, diff)
+		}
+		if th.Status != ThreadStatusActive {
+			t.Errorf("thread %d status = %q, want %q", i, th.Status, ThreadStatusActive)
+		}
+		if got, want := th.Properties[fingerprintProperty].Value, wantFingerprints[i]; got != want {
+			t.Errorf("thread %d fingerprint = %q, want %q", i, got, want)
+		}
+	}
+	if got, want := created[3].Comments[0].Content, "**[tool2]** <sub>reported by [reviewdog](https://github.com/reviewdog/reviewdog) :dog:</sub><br>not in diff\n\n`other.go:10`"; got != want {
+		t.Errorf("general thread content = %q, want %q", got, want)
+	}
+	if diff := cmp.Diff(map[int]string{2: ThreadStatusActive, 3: ThreadStatusFixed}, updated); diff != "" {
+		t.Errorf("updated statuses diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	newComment := func(line int32) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: "message",
+				},
+			},
+		}
+	}
+	comments := []*reviewdog.Comment{newComment(40), newComment(10), newComment(40)}
+	fp := commentutil.Fingerprint(comments[0])
+	want := []string{fp + "-1", fp + "-0", fp + "-1"}
+	if diff := cmp.Diff(want, fingerprints(comments)); diff != "" {
+		t.Errorf("fingerprints diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,71 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+var _ reviewdog.DiffService = &PullRequestDiff{}
+
+// PullRequestDiff is a diff service for Azure Repos pull requests.
+type PullRequestDiff struct {
+	cli *Client
+	pr  int
+}
+
+// NewPullRequestDiff returns a new PullRequestDiff service.
+// PullRequestDiff service needs git command in $PATH.
+func NewPullRequestDiff(cli *Client, pr int) *PullRequestDiff {
+	return &PullRequestDiff{cli: cli, pr: pr}
+}
+
+// Diff returns a diff of the pull request. It runs `git diff --find-renames`
+// locally between the merge base and the source commit of the last merge of
+// the pull request, as Azure DevOps API doesn't provide a diff in git format.
+// Azure Pipelines checks out the merge commit, whose parents are the commits.
+// It needs the full history of them to find the merge base, so it fails with
+// a shallow checkout (e.g. fetchDepth: 1 of the checkout step).
+func (p *PullRequestDiff) Diff(ctx context.Context) ([]byte, error) {
+	pr, err := p.cli.GetPullRequest(ctx, p.pr)
+	if err != nil {
+		return nil, err
+	}
+	if pr.LastMergeSourceCommit == nil || pr.LastMergeTargetCommit == nil {
+		return nil, fmt.Errorf("pull request %d doesn't have merge commits", p.pr)
+	}
+	source, target := pr.LastMergeSourceCommit.CommitID, pr.LastMergeTargetCommit.CommitID
+	for _, sha := range []string{target, source} {
+		if err := exec.CommandContext(ctx, "git", "cat-file", "-e", sha+"^{commit}").Run(); err != nil {
+			return nil, fmt.Errorf("commit %s of pull request %d is not fetched. "+
+				"Fetch the full history (e.g. fetchDepth: 0 of the checkout step): %w", sha, p.pr, err)
+		}
+	}
+	b, err := exec.CommandContext(ctx, "git", "merge-base", target, source).Output()
+	if err != nil {
+		if isShallowRepository(ctx) {
+			return nil, fmt.Errorf("failed to get merge-base commit in shallow repository. "+
+				"Fetch the full history (e.g. fetchDepth: 0 of the checkout step): %w", err)
+		}
+		return nil, fmt.Errorf("failed to get merge-base commit: %w", err)
+	}
+	mergeBase := strings.Trim(string(b), "\n")
+	d, err := exec.CommandContext(ctx, "git", "diff", "--find-renames", mergeBase, source).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git diff: %w", err)
+	}
+	return d, nil
+}
+
+func isShallowRepository(ctx context.Context) bool {
+	b, err := exec.CommandContext(ctx, "git", "rev-parse", "--is-shallow-repository").Output()
+	return err == nil && strings.TrimSpace(string(b)) == "true"
+}
+
+// Strip returns 1 as a strip of git diff.
+func (p *PullRequestDiff) Strip() int {
+	return 1
+}
@@ -0,0 +1,51 @@
+package azuredevops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPullRequestDiff_Diff(t *testing.T) {
+	getPRAPICall := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/project/_apis/git/repositories/repo/pullRequests/14", func(w http.ResponseWriter, r *http.Request) {
+		getPRAPICall++
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		if got := r.URL.Query().Get("api-version"); got != apiVersion {
+			t.Errorf("api-version = %q, want %q", got, apiVersion)
+		}
+		w.Write([]byte(`{"pullRequestId": 14, "lastMergeSourceCommit": {"commitId": "HEAD"}, "lastMergeTargetCommit": {"commitId": "HEAD~"}}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli := NewClient(nil, ts.URL+"/org/", "project", "repo", "")
+	d := NewPullRequestDiff(cli, 14)
+	if _, err := d.Diff(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if getPRAPICall != 1 {
+		t.Errorf("Get pull request API called %v times, want once", getPRAPICall)
+	}
+}
+
+func TestPullRequestDiff_Diff_notFetched(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/project/_apis/git/repositories/repo/pullRequests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"pullRequestId": 14, "lastMergeSourceCommit": {"commitId": "0000000000000000000000000000000000000001"}, "lastMergeTargetCommit": {"commitId": "HEAD"}}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli := NewClient(nil, ts.URL+"/org/", "project", "repo", "")
+	d := NewPullRequestDiff(cli, 14)
+	_, err := d.Diff(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "fetchDepth: 0") {
+		t.Errorf("got error %v, want error about the full history", err)
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 var _ reviewdog.CommentService = &ReportAnnotator{}
@@ -41,10 +45,67 @@ type ReportAnnotator struct {
 	// wd is working directory relative to root of repository.
 	wd         string
 	duplicates map[string]struct{}
+
+	opt ReportOption
+	// start is used to report duration of the run
+	start time.Time
+	now   func() time.Time
+}
+
+// ReportOption is an option of reports created by ReportAnnotator
+type ReportOption struct {
+	// ReportTypes maps runners to report types
+	// (BUG, SECURITY, COVERAGE or TEST). BUG by default.
+	ReportTypes map[string]string
+	// AnnotationTypes maps runners to annotation types
+	// (CODE_SMELL, VULNERABILITY or BUG). CODE_SMELL by default.
+	AnnotationTypes map[string]string
+	// Level is the report level (info, warning or error) which is used as
+	// severity of results without severity.
+	Level string
+}
+
+// ParseReportTypes parses mapping of runners to report types
+// in "runner1=SECURITY,runner2=TEST" format
+func ParseReportTypes(s string) (map[string]string, error) {
+	return parseRunnerTypes(s, reportTypeBug, reportTypeSecurity, reportTypeCoverage, reportTypeTest)
+}
+
+// ParseAnnotationTypes parses mapping of runners to annotation types
+// in "runner1=VULNERABILITY,runner2=BUG" format
+func ParseAnnotationTypes(s string) (map[string]string, error) {
+	return parseRunnerTypes(s, annotationTypeCodeSmell, annotationTypeVulnerability, annotationTypeBug)
+}
+
+func parseRunnerTypes(s string, validTypes ...string) (map[string]string, error) {
+	types := make(map[string]string)
+	if s == "" {
+		return types, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid runner type %q, must be in runner=TYPE format", pair)
+		}
+
+		runner, typ := kv[0], strings.ToUpper(kv[1])
+		valid := false
+		for _, t := range validTypes {
+			valid = valid || typ == t
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid type %q of runner %q, must be one of %s",
+				typ, runner, strings.Join(validTypes, ", "))
+		}
+		types[runner] = typ
+	}
+
+	return types, nil
 }
 
 // NewReportAnnotator creates new Bitbucket ReportRequest Annotator
-func NewReportAnnotator(cli APIClient, owner, repo, sha string, runners []string) *ReportAnnotator {
+func NewReportAnnotator(cli APIClient, owner, repo, sha string, runners []string, opt ReportOption) *ReportAnnotator {
 	r := &ReportAnnotator{
 		cli:        cli,
 		sha:        sha,
@@ -52,7 +113,10 @@ func NewReportAnnotator(cli APIClient, owner, repo, sha string, runners []string
 		repo:       repo,
 		comments:   make(map[string][]*reviewdog.Comment, len(runners)),
 		duplicates: map[string]struct{}{},
+		opt:        opt,
+		now:        time.Now,
 	}
+	r.start = r.now()
 
 	// pre populate map of annotations, so we still create passed (green) report
 	// if no issues found from the specific tool
@@ -62,12 +126,7 @@ func NewReportAnnotator(cli APIClient, owner, repo, sha string, runners []string
 		}
 		r.comments[runner] = []*reviewdog.Comment{}
 		// create Pending report for each tool
-		_ = r.createOrUpdateReport(
-			context.Background(),
-			reportID(runner, reporter),
-			reportTitle(runner, reporter),
-			reportResultPending,
-		)
+		_ = r.createOrUpdateReport(context.Background(), runner, reportResultPending, nil)
 	}
 
 	return r
@@ -101,11 +160,9 @@ func (r *ReportAnnotator) Flush(ctx context.Context) error {
 
 	// create/update/annotate report per tool
 	for tool, comments := range r.comments {
-		reportID := reportID(tool, reporter)
-		title := reportTitle(tool, reporter)
 		if len(comments) == 0 {
 			// if no annotation, create Passed report
-			if err := r.createOrUpdateReport(ctx, reportID, title, reportResultPassed); err != nil {
+			if err := r.createOrUpdateReport(ctx, tool, reportResultPassed, nil); err != nil {
 				return err
 			}
 			// and move one
@@ -113,7 +170,7 @@ func (r *ReportAnnotator) Flush(ctx context.Context) error {
 		}
 
 		// create report or update report first, with the failed status
-		if err := r.createOrUpdateReport(ctx, reportID, title, reportResultFailed); err != nil {
+		if err := r.createOrUpdateReport(ctx, tool, reportResultFailed, comments); err != nil {
 			return err
 		}
 
@@ -129,7 +186,9 @@ func (r *ReportAnnotator) Flush(ctx context.Context) error {
 				Owner:      r.owner,
 				Repository: r.repo,
 				Commit:     r.sha,
-				ReportID:   reportID,
+				ReportID:   reportID(tool, reporter),
+				Type:       r.annotationType(tool),
+				Level:      r.opt.Level,
 				Comments:   comments[start:end],
 			}
 
@@ -143,27 +202,57 @@ func (r *ReportAnnotator) Flush(ctx context.Context) error {
 	return nil
 }
 
-func (r *ReportAnnotator) createOrUpdateReport(ctx context.Context, id, title, reportStatus string) error {
+func (r *ReportAnnotator) createOrUpdateReport(ctx context.Context, tool, reportStatus string, comments []*reviewdog.Comment) error {
 	req := &ReportRequest{
-		ReportID:   id,
+		ReportID:   reportID(tool, reporter),
 		Owner:      r.owner,
 		Repository: r.repo,
 		Commit:     r.sha,
-		Type:       reportTypeBug,
-		Title:      title,
+		Type:       r.reportType(tool),
+		Title:      reportTitle(tool, reporter),
 		Reporter:   reporter,
 		Result:     reportStatus,
 		LogoURL:    logoURL,
 	}
 
+	counts := make(serviceutil.SeverityCounts)
+	for _, c := range comments {
+		counts[serviceutil.Severity(c.Result.Diagnostic, r.opt.Level)]++
+	}
 	switch reportStatus {
 	case reportResultPassed:
 		req.Details = "Great news! Reviewdog couldn't spot any issues!"
 	case reportResultPending:
 		req.Details = "Please wait for Reviewdog to finish checking your code for issues."
 	default:
-		req.Details = "Woof-Woof! This report generated for you by reviewdog."
+		req.Details = fmt.Sprintf("Woof-Woof! Reviewdog found %d issue(s): %d error(s), %d warning(s) and %d info.",
+			len(comments), counts[rdf.Severity_ERROR], counts[rdf.Severity_WARNING], counts[rdf.Severity_INFO])
+	}
+
+	if reportStatus != reportResultPending {
+		req.Data = []ReportData{
+			{Title: "Issues", Type: reportDataTypeNumber, Value: len(comments)},
+			{Title: "Errors", Type: reportDataTypeNumber, Value: counts[rdf.Severity_ERROR]},
+			{Title: "Warnings", Type: reportDataTypeNumber, Value: counts[rdf.Severity_WARNING]},
+			{Title: "Info", Type: reportDataTypeNumber, Value: counts[rdf.Severity_INFO]},
+			// duration is in milliseconds
+			{Title: "Duration", Type: reportDataTypeDuration, Value: r.now().Sub(r.start).Milliseconds()},
+		}
 	}
 
 	return r.cli.CreateOrUpdateReport(ctx, req)
 }
+
+func (r *ReportAnnotator) reportType(tool string) string {
+	if t, ok := r.opt.ReportTypes[tool]; ok {
+		return t
+	}
+	return reportTypeBug
+}
+
+func (r *ReportAnnotator) annotationType(tool string) string {
+	if t, ok := r.opt.AnnotationTypes[tool]; ok {
+		return t
+	}
+	return annotationTypeCodeSmell
+}
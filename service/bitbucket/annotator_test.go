@@ -2,13 +2,16 @@ package bitbucket
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/proto/rdf"
 
 	"github.com/reviewdog/reviewdog"
 
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -18,6 +21,7 @@ type AnnotatorTestSuite struct {
 	owner string
 	repo  string
 	sha   string
+	opt   ReportOption
 }
 
 func (s *AnnotatorTestSuite) SetupTest() {
@@ -25,6 +29,7 @@ func (s *AnnotatorTestSuite) SetupTest() {
 	s.owner = "test-owner"
 	s.repo = "test-repo"
 	s.sha = "test-commit"
+	s.opt = ReportOption{Level: "warning"}
 }
 
 // Empty runners list, no comments
@@ -117,6 +122,58 @@ func (s *AnnotatorTestSuite) TestManyComments() {
 	s.cli.AssertExpectations(s.T())
 }
 
+// Report and annotation types mapped per runner
+func (s *AnnotatorTestSuite) TestRunnerTypes() {
+	runners := []string{"runner1", "runner2"}
+	s.opt = ReportOption{
+		Level:           "warning",
+		ReportTypes:     map[string]string{"runner1": reportTypeSecurity},
+		AnnotationTypes: map[string]string{"runner1": annotationTypeVulnerability},
+	}
+	comments := []*reviewdog.Comment{
+		s.buildComment(runners[0], 1),
+		s.buildComment(runners[1], 2),
+	}
+
+	ctx, annotator := s.createAnnotator(runners)
+	s.setupExpectedAPICalls(ctx, runners, comments)
+
+	for _, comment := range comments {
+		err := annotator.Post(ctx, comment)
+		s.Require().NoError(err)
+	}
+
+	err := annotator.Flush(ctx)
+
+	s.Require().NoError(err)
+	s.cli.AssertExpectations(s.T())
+}
+
+// Results without severity are counted as the report level
+func (s *AnnotatorTestSuite) TestSeverities() {
+	runners := []string{"runner1"}
+	comments := []*reviewdog.Comment{
+		s.buildComment(runners[0], 1),
+		s.buildComment(runners[0], 2),
+		s.buildComment(runners[0], 3),
+	}
+	comments[0].Result.Diagnostic.Severity = rdf.Severity_ERROR
+	comments[2].Result.Diagnostic.Severity = rdf.Severity_INFO
+
+	ctx, annotator := s.createAnnotator(runners)
+	s.setupExpectedAPICalls(ctx, runners, comments)
+
+	for _, comment := range comments {
+		err := annotator.Post(ctx, comment)
+		s.Require().NoError(err)
+	}
+
+	err := annotator.Flush(ctx)
+
+	s.Require().NoError(err)
+	s.cli.AssertExpectations(s.T())
+}
+
 func (s *AnnotatorTestSuite) createAnnotator(runners []string) (context.Context, *ReportAnnotator) {
 	ctx := context.Background()
 
@@ -124,7 +181,9 @@ func (s *AnnotatorTestSuite) createAnnotator(runners []string) (context.Context,
 		s.assumeReportCreated(ctx, runner, reportResultPending)
 	}
 
-	annotator := NewReportAnnotator(s.cli, s.owner, s.repo, s.sha, runners)
+	annotator := NewReportAnnotator(s.cli, s.owner, s.repo, s.sha, runners, s.opt)
+	// report the fixed duration
+	annotator.now = func() time.Time { return annotator.start.Add(1500 * time.Millisecond) }
 
 	return ctx, annotator
 }
@@ -141,7 +200,7 @@ func (s *AnnotatorTestSuite) setupExpectedAPICalls(
 		if len(commentsMap[runner]) > 0 {
 			expResult = reportResultFailed
 		}
-		s.assumeReportCreated(ctx, runner, expResult)
+		s.assumeReportCreated(ctx, runner, expResult, commentsMap[runner]...)
 
 		for start, annCount := 0, len(commentsMap[runner]); start < annCount; start += annotationsBatchSize {
 			end := start + annotationsBatchSize
@@ -155,8 +214,8 @@ func (s *AnnotatorTestSuite) setupExpectedAPICalls(
 	}
 }
 
-func (s *AnnotatorTestSuite) assumeReportCreated(ctx context.Context, runner string, status string) {
-	s.cli.On("CreateOrUpdateReport", ctx, s.buildReportReq(runner, status)).Return(nil).Once()
+func (s *AnnotatorTestSuite) assumeReportCreated(ctx context.Context, runner string, status string, comments ...*reviewdog.Comment) {
+	s.cli.On("CreateOrUpdateReport", ctx, s.buildReportReq(runner, status, comments)).Return(nil).Once()
 }
 
 func (s *AnnotatorTestSuite) assumeAnnotationsCreated(
@@ -167,37 +226,68 @@ func (s *AnnotatorTestSuite) assumeAnnotationsCreated(
 	s.cli.On("CreateOrUpdateAnnotations", ctx, s.buildAnnotationsRequest(runner, comments)).Return(nil).Once()
 }
 
-func (s *AnnotatorTestSuite) buildReportReq(runner string, result string) *ReportRequest {
+func (s *AnnotatorTestSuite) buildReportReq(runner string, result string, comments []*reviewdog.Comment) *ReportRequest {
+	reportType := reportTypeBug
+	if t, ok := s.opt.ReportTypes[runner]; ok {
+		reportType = t
+	}
 	report := &ReportRequest{
 		ReportID:   reportID(runner, reporter),
 		Owner:      s.owner,
 		Repository: s.repo,
 		Commit:     s.sha,
-		Type:       reportTypeBug,
+		Type:       reportType,
 		Title:      reportTitle(runner, reporter),
 		Reporter:   reporter,
 		Result:     result,
 		LogoURL:    logoURL,
 	}
 
+	// results without severity are warnings as the report level is "warning"
+	counts := make(map[rdf.Severity]int)
+	for _, c := range comments {
+		sv := c.Result.Diagnostic.GetSeverity()
+		if sv == rdf.Severity_UNKNOWN_SEVERITY {
+			sv = rdf.Severity_WARNING
+		}
+		counts[sv]++
+	}
+
 	switch result {
 	case reportResultPassed:
 		report.Details = "Great news! Reviewdog couldn't spot any issues!"
 	case reportResultPending:
 		report.Details = "Please wait for Reviewdog to finish checking your code for issues."
 	default:
-		report.Details = "Woof-Woof! This report generated for you by reviewdog."
+		report.Details = fmt.Sprintf("Woof-Woof! Reviewdog found %d issue(s): %d error(s), %d warning(s) and %d info.",
+			len(comments), counts[rdf.Severity_ERROR], counts[rdf.Severity_WARNING], counts[rdf.Severity_INFO])
+	}
+
+	if result != reportResultPending {
+		report.Data = []ReportData{
+			{Title: "Issues", Type: reportDataTypeNumber, Value: len(comments)},
+			{Title: "Errors", Type: reportDataTypeNumber, Value: counts[rdf.Severity_ERROR]},
+			{Title: "Warnings", Type: reportDataTypeNumber, Value: counts[rdf.Severity_WARNING]},
+			{Title: "Info", Type: reportDataTypeNumber, Value: counts[rdf.Severity_INFO]},
+			{Title: "Duration", Type: reportDataTypeDuration, Value: int64(1500)},
+		}
 	}
 
 	return report
 }
 
 func (s *AnnotatorTestSuite) buildAnnotationsRequest(runner string, comments []*reviewdog.Comment) *AnnotationsRequest {
+	annotationType := annotationTypeCodeSmell
+	if t, ok := s.opt.AnnotationTypes[runner]; ok {
+		annotationType = t
+	}
 	return &AnnotationsRequest{
 		Owner:      s.owner,
 		Repository: s.repo,
 		Commit:     s.sha,
 		ReportID:   reportID(runner, reporter),
+		Type:       annotationType,
+		Level:      s.opt.Level,
 		Comments:   comments,
 	}
 }
@@ -234,6 +324,18 @@ func (s *AnnotatorTestSuite) splitComments(comments []*reviewdog.Comment) map[st
 	return commentsMap
 }
 
+func TestParseReportTypes(t *testing.T) {
+	got, err := ParseReportTypes("gosec=SECURITY, go-test=test")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"gosec": reportTypeSecurity, "go-test": reportTypeTest}, got)
+
+	_, err = ParseReportTypes("gosec=VULNERABILITY")
+	require.Error(t, err)
+
+	_, err = ParseAnnotationTypes("gosec")
+	require.Error(t, err)
+}
+
 func TestAnnotatorTestSuite(t *testing.T) {
 	suite.Run(t, &AnnotatorTestSuite{})
 }
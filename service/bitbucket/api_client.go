@@ -19,6 +19,14 @@ type ReportRequest struct {
 	Result     string
 	Details    string
 	LogoURL    string
+	Data       []ReportData
+}
+
+// ReportData is a data field shown in the report, e.g. number of issues
+type ReportData struct {
+	Title string      `json:"title"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
 }
 
 // AnnotationsRequest is an object that represent parameters used to create/update annotations
@@ -27,6 +35,8 @@ type AnnotationsRequest struct {
 	Repository string
 	Commit     string
 	ReportID   string
+	Type       string
+	Level      string // used as severity of results without severity
 	Comments   []*reviewdog.Comment
 }
 
@@ -84,14 +84,25 @@ func NewCloudAPIClientWithConfigurations(client *http.Client, server bbapi.Serve
 	}
 }
 
-// CreateOrUpdateReport creates or updates specified report
+// CreateOrUpdateReport creates or updates specified report.
+// It sends the request without the API client because the client doesn't
+// support numeric values of report data.
 func (c *CloudAPIClient) CreateOrUpdateReport(ctx context.Context, req *ReportRequest) error {
-	_, resp, err := c.cli.
-		ReportsApi.CreateOrUpdateReport(ctx, req.Owner, req.Repository, req.Commit, req.ReportID).
-		Body(c.helper.BuildReport(req)).
-		Execute()
+	body, err := buildReportBody(c.helper.BuildReport(req), req.Data)
+	if err != nil {
+		return err
+	}
 
-	if err := c.checkAPIError(err, resp, http.StatusOK); err != nil {
+	config := c.cli.GetConfig()
+	u := fmt.Sprintf("%s/repositories/%s/%s/commit/%s/reports/%s", config.Servers[0].URL,
+		url.PathEscape(req.Owner), url.PathEscape(req.Repository), url.PathEscape(req.Commit), url.PathEscape(req.ReportID))
+	httpReq, err := newRequest(ctx, http.MethodPut, u, body)
+	if err != nil {
+		return err
+	}
+	setCloudAuth(httpReq)
+
+	if _, err := doRequest(config.HTTPClient, httpReq, http.StatusOK); err != nil {
 		return fmt.Errorf("failed to create code insights report: %w", err)
 	}
 
@@ -102,7 +113,7 @@ func (c *CloudAPIClient) CreateOrUpdateReport(ctx context.Context, req *ReportRe
 func (c *CloudAPIClient) CreateOrUpdateAnnotations(ctx context.Context, req *AnnotationsRequest) error {
 	_, resp, err := c.cli.ReportsApi.
 		BulkCreateOrUpdateAnnotations(ctx, req.Owner, req.Repository, req.Commit, req.ReportID).
-		Body(c.helper.BuildAnnotations(req.Comments)).
+		Body(c.helper.BuildAnnotations(req.Comments, req.Type, req.Level)).
 		Execute()
 
 	if err := c.checkAPIError(err, resp, http.StatusOK); err != nil {
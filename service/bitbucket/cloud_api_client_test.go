@@ -0,0 +1,97 @@
+package bitbucket
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bbapi "github.com/reviewdog/go-bitbucket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCloudAPIClient(t *testing.T) {
+	var report, annotations string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/o/r/commit/sha/reports/id", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+			t.Errorf("unexpected credentials: %q, %q", user, pass)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		report = string(b)
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/repositories/o/r/commit/sha/reports/id/annotations", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		annotations = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli := NewCloudAPIClientWithConfigurations(nil, bbapi.ServerConfiguration{URL: ts.URL})
+	ctx := BuildCloudAPIContext(context.Background(), "user", "pass", "")
+
+	err := cli.CreateOrUpdateReport(ctx, &ReportRequest{
+		Owner:      "o",
+		Repository: "r",
+		Commit:     "sha",
+		ReportID:   "id",
+		Type:       reportTypeSecurity,
+		Title:      "title",
+		Reporter:   reporter,
+		Result:     reportResultFailed,
+		Details:    "details",
+		Data: []ReportData{
+			{Title: "Issues", Type: reportDataTypeNumber, Value: 1},
+			{Title: "Duration", Type: reportDataTypeDuration, Value: int64(1500)},
+		},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "",
+		"title": "title",
+		"details": "details",
+		"reporter": "reviewdog",
+		"logo_url": "",
+		"report_type": "SECURITY",
+		"result": "FAILED",
+		"data": [
+			{"title": "Issues", "type": "NUMBER", "value": 1},
+			{"title": "Duration", "type": "DURATION", "value": 1500}
+		]
+	}`, report)
+
+	err = cli.CreateOrUpdateAnnotations(ctx, &AnnotationsRequest{
+		Owner:      "o",
+		Repository: "r",
+		Commit:     "sha",
+		ReportID:   "id",
+		Type:       annotationTypeVulnerability,
+		Comments: []*reviewdog.Comment{{
+			ToolName: "gosec",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "main.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Message:  "message",
+					Severity: rdf.Severity_ERROR,
+					Code:     &rdf.Code{Value: "G101", Url: "https://example.com/G101"},
+				},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, annotations, `"annotation_type":"VULNERABILITY"`)
+	require.Contains(t, annotations, `"result":"FAILED"`)
+	require.Contains(t, annotations, `"link":"https://example.com/G101"`)
+}
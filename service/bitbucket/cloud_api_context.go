@@ -2,6 +2,7 @@ package bitbucket
 
 import (
 	"context"
+	"net/http"
 
 	bbapi "github.com/reviewdog/go-bitbucket"
 )
@@ -32,3 +33,15 @@ func withBasicAuth(ctx context.Context, username, password string) context.Conte
 func withAccessToken(ctx context.Context, accessToken string) context.Context {
 	return context.WithValue(ctx, bbapi.ContextAccessToken, accessToken)
 }
+
+// setCloudAuth sets credentials in context built by BuildCloudAPIContext to
+// the request which isn't sent by Code Insights API client
+func setCloudAuth(req *http.Request) {
+	ctx := req.Context()
+	if auth, ok := ctx.Value(bbapi.ContextBasicAuth).(bbapi.BasicAuth); ok {
+		req.SetBasicAuth(auth.UserName, auth.Password)
+	}
+	if token, ok := ctx.Value(bbapi.ContextAccessToken).(string); ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
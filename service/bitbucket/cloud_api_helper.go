@@ -5,6 +5,7 @@ import (
 
 	bbapi "github.com/reviewdog/go-bitbucket"
 	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 // CloudAPIHelper is collection of utility functions used to build requests
@@ -24,26 +25,32 @@ func (c *CloudAPIHelper) BuildReport(req *ReportRequest) bbapi.Report {
 	return *data
 }
 
-// BuildAnnotations builds list of Code Insights API annotation objects for specified comments
-func (c *CloudAPIHelper) BuildAnnotations(comments []*reviewdog.Comment) []bbapi.ReportAnnotation {
+// BuildAnnotations builds list of Code Insights API annotation objects for specified comments.
+// Code Insights API doesn't support end lines of annotations.
+func (c *CloudAPIHelper) BuildAnnotations(comments []*reviewdog.Comment, annotationType, level string) []bbapi.ReportAnnotation {
 	annotations := make([]bbapi.ReportAnnotation, len(comments))
 	for idx, comment := range comments {
-		annotations[idx] = c.buildAnnotation(comment)
+		annotations[idx] = c.buildAnnotation(comment, annotationType, level)
 	}
 
 	return annotations
 }
 
-func (c *CloudAPIHelper) buildAnnotation(comment *reviewdog.Comment) bbapi.ReportAnnotation {
+func (c *CloudAPIHelper) buildAnnotation(comment *reviewdog.Comment, annotationType, level string) bbapi.ReportAnnotation {
+	if annotationType == "" {
+		annotationType = annotationTypeCodeSmell
+	}
+
 	data := bbapi.NewReportAnnotation()
 	data.SetExternalId(externalIDFromDiagnostic(comment.Result.Diagnostic))
-	data.SetAnnotationType(annotationTypeCodeSmell)
+	data.SetAnnotationType(annotationType)
+	data.SetResult(annotationResultFailed)
 	data.SetSummary(comment.Result.Diagnostic.GetMessage())
 	data.SetDetails(fmt.Sprintf(`[%s] %s`, comment.ToolName, comment.Result.Diagnostic.GetMessage()))
 	data.SetLine(comment.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
 	data.SetPath(comment.Result.Diagnostic.GetLocation().GetPath())
 
-	if severity := convertSeverity(comment.Result.Diagnostic.GetSeverity()); severity != "" {
+	if severity := convertSeverity(serviceutil.Severity(comment.Result.Diagnostic, level)); severity != "" {
 		data.SetSeverity(severity)
 	}
 
@@ -0,0 +1,153 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const cloudAPIURL = "https://api.bitbucket.org/2.0"
+
+// CloudPullRequestClient is client for Bitbucket Cloud pull request API.
+// Unlike Code Insights API, pull request API isn't available via the
+// authentication proxy of Bitbucket Pipelines, so it needs credentials
+// in context built by BuildCloudAPIContext.
+type CloudPullRequestClient struct {
+	cli     *http.Client
+	baseURL string
+}
+
+type cloudComment struct {
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Inline  *cloudCommentInline `json:"inline,omitempty"`
+	Deleted bool                `json:"deleted,omitempty"`
+}
+
+type cloudCommentInline struct {
+	Path string `json:"path"`
+	From int    `json:"from,omitempty"`
+	To   int    `json:"to,omitempty"`
+}
+
+type cloudCommentsPage struct {
+	Values []*cloudComment `json:"values"`
+	Next   string          `json:"next"`
+}
+
+// NewCloudPullRequestClient creates client for Bitbucket Cloud pull request API
+func NewCloudPullRequestClient() PullRequestClient {
+	return NewCloudPullRequestClientWithURL(nil, cloudAPIURL)
+}
+
+// NewCloudPullRequestClientWithURL creates client for Bitbucket Cloud pull request API with specified base URL
+func NewCloudPullRequestClientWithURL(client *http.Client, baseURL string) PullRequestClient {
+	if client == nil {
+		client = &http.Client{
+			Timeout: httpTimeout,
+		}
+	}
+
+	return &CloudPullRequestClient{
+		cli:     client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// GetPullRequestDiff returns diff of pull request in git diff format
+func (c *CloudPullRequestClient) GetPullRequestDiff(ctx context.Context, owner, repo string, pr int) ([]byte, error) {
+	req, err := newRequest(ctx, http.MethodGet, c.pullRequestURL(owner, repo, pr)+"/diff", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request diff: %w", err)
+	}
+
+	return diff, nil
+}
+
+// ListPullRequestComments returns all comments of pull request
+func (c *CloudPullRequestClient) ListPullRequestComments(ctx context.Context, owner, repo string, pr int) ([]*PullRequestComment, error) {
+	var comments []*PullRequestComment
+	next := c.pullRequestURL(owner, repo, pr) + "/comments?pagelen=100"
+	for next != "" {
+		req, err := newRequest(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.do(req, http.StatusOK)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull request comments: %w", err)
+		}
+
+		var page cloudCommentsPage
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse pull request comments: %w", err)
+		}
+
+		for _, v := range page.Values {
+			if v.Deleted {
+				continue
+			}
+			comment := &PullRequestComment{Body: v.Content.Raw}
+			if v.Inline != nil {
+				comment.Path = v.Inline.Path
+				comment.Line = v.Inline.To
+				comment.LineType = lineTypeAdded
+				if v.Inline.To == 0 {
+					comment.Line = v.Inline.From
+					comment.LineType = lineTypeRemoved
+				}
+			}
+			comments = append(comments, comment)
+		}
+		next = page.Next
+	}
+
+	return comments, nil
+}
+
+// CreatePullRequestComment creates comment on pull request
+func (c *CloudPullRequestClient) CreatePullRequestComment(ctx context.Context, owner, repo string, pr int, comment *PullRequestComment) error {
+	body := &cloudComment{}
+	body.Content.Raw = comment.Body
+	if comment.IsInline() {
+		body.Inline = &cloudCommentInline{Path: comment.Path}
+		if comment.LineType == lineTypeRemoved {
+			body.Inline.From = comment.Line
+		} else {
+			body.Inline.To = comment.Line
+		}
+	}
+
+	req, err := newRequest(ctx, http.MethodPost, c.pullRequestURL(owner, repo, pr)+"/comments", body)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.do(req, http.StatusCreated); err != nil {
+		return fmt.Errorf("failed to create pull request comment: %w", err)
+	}
+
+	return nil
+}
+
+func (c *CloudPullRequestClient) pullRequestURL(owner, repo string, pr int) string {
+	return fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d",
+		c.baseURL, url.PathEscape(owner), url.PathEscape(repo), pr)
+}
+
+// do sends request with credentials in the context
+func (c *CloudPullRequestClient) do(req *http.Request, expectedCode int) ([]byte, error) {
+	setCloudAuth(req)
+
+	return doRequest(c.cli, req, expectedCode)
+}
@@ -2,6 +2,7 @@ package bitbucket
 
 import (
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -52,3 +53,22 @@ func convertSeverity(severity rdf.Severity) string {
 		return ""
 	}
 }
+
+// buildReportBody adds data fields to the report object of Code Insights API.
+// Neither of API clients supports numeric values of report data.
+func buildReportBody(report interface{}, data []ReportData) (map[string]interface{}, error) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make(map[string]interface{})
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		body["data"] = data
+	}
+
+	return body, nil
+}
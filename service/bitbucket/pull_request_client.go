@@ -0,0 +1,97 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Line types of inline pull request comments
+const (
+	lineTypeAdded   = "ADDED"
+	lineTypeContext = "CONTEXT"
+	lineTypeRemoved = "REMOVED"
+)
+
+// PullRequestComment is a comment on pull request
+type PullRequestComment struct {
+	Body string
+
+	// Path and Line are the position of inline comment.
+	// They are empty for general comments.
+	Path string
+	Line int
+	// LineType is one of ADDED, CONTEXT and REMOVED.
+	// Line is a line number of the old file if it's REMOVED.
+	LineType string
+	// SrcPath is the old path of renamed file. Optional.
+	SrcPath string
+}
+
+// IsInline returns true if the comment is an inline comment on the diff
+func (c *PullRequestComment) IsInline() bool {
+	return c.Path != "" && c.Line != 0
+}
+
+// PullRequestClient is client for Bitbucket pull request API.
+// Neither of Code Insights API clients supports pull requests.
+type PullRequestClient interface {
+
+	// GetPullRequestDiff returns diff of pull request in git diff format
+	GetPullRequestDiff(ctx context.Context, owner, repo string, pr int) ([]byte, error)
+
+	// ListPullRequestComments returns all comments of pull request
+	ListPullRequestComments(ctx context.Context, owner, repo string, pr int) ([]*PullRequestComment, error)
+
+	// CreatePullRequestComment creates comment on pull request
+	CreatePullRequestComment(ctx context.Context, owner, repo string, pr int, comment *PullRequestComment) error
+}
+
+// newRequest creates a request with JSON encoded body if it's not nil
+func newRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// doRequest sends a request and returns the response body.
+// It returns UnexpectedResponseError if the status code is not expected one.
+func doRequest(cli *http.Client, req *http.Request, expectedCode int) ([]byte, error) {
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != expectedCode {
+		return nil, UnexpectedResponseError{
+			Code: resp.StatusCode,
+			Body: body,
+		}
+	}
+
+	return body, nil
+}
@@ -0,0 +1,214 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/diff"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.CommentService = &PullRequestCommenter{}
+var _ reviewdog.DiffService = &PullRequestCommenter{}
+
+// bodySuffix is appended to comment body. Bitbucket doesn't render HTML in
+// comments, so it doesn't use commentutil.BodyPrefix.
+const bodySuffix = "\n\n*reported by [reviewdog](https://github.com/reviewdog/reviewdog) :dog:*"
+
+// PullRequestCommenter is a comment and diff service for Bitbucket pull requests.
+//
+// Cloud API:
+//  https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/#api-repositories-workspace-repo-slug-pullrequests-pull-request-id-comments-post
+//  POST /2.0/repositories/{workspace}/{repo_slug}/pullrequests/{pull_request_id}/comments
+//
+// Server API:
+//  https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html
+//  POST /rest/api/1.0/projects/{projectKey}/repos/{repositorySlug}/pull-requests/{pullRequestId}/comments
+type PullRequestCommenter struct {
+	*PullRequestDiff
+
+	// lineTypes holds line type (ADDED or CONTEXT) of lines in the new files
+	// of the diff per path.
+	lineTypes map[string]map[int]string
+	// renames maps old paths of renamed files to new paths.
+	renames map[string]string
+
+	muComments   sync.Mutex
+	postComments []*reviewdog.Comment
+
+	// wd is working directory relative to root of repository.
+	wd string
+}
+
+// NewPullRequestCommenter returns a new PullRequestCommenter service.
+// PullRequestCommenter service needs git command in $PATH.
+func NewPullRequestCommenter(cli PullRequestClient, owner, repo string, pr int) (*PullRequestCommenter, error) {
+	workDir, err := serviceutil.GitRelWorkdir()
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestCommenter needs 'git' command: %w", err)
+	}
+
+	return &PullRequestCommenter{
+		PullRequestDiff: NewPullRequestDiff(cli, owner, repo, pr),
+		wd:              workDir,
+	}, nil
+}
+
+// Post accepts a comment and holds it. Flush method actually posts comments to
+// Bitbucket.
+func (p *PullRequestCommenter) Post(_ context.Context, c *reviewdog.Comment) error {
+	c.Result.Diagnostic.GetLocation().Path = filepath.ToSlash(
+		filepath.Join(p.wd, c.Result.Diagnostic.GetLocation().GetPath()))
+	p.muComments.Lock()
+	defer p.muComments.Unlock()
+	p.postComments = append(p.postComments, c)
+	return nil
+}
+
+// Flush posts comments which has not been posted yet as inline comments.
+// Results outside the diff are not posted, so that they don't flood the pull
+// request with general comments. They are reported to console by the other
+// comment service.
+func (p *PullRequestCommenter) Flush(ctx context.Context) error {
+	p.muComments.Lock()
+	defer p.muComments.Unlock()
+
+	if len(p.postComments) == 0 {
+		return nil
+	}
+
+	if err := p.setLineTypes(ctx); err != nil {
+		return err
+	}
+
+	comments, err := p.cli.ListPullRequestComments(ctx, p.owner, p.repo, p.pr)
+	if err != nil {
+		return err
+	}
+	postedcs := make(commentutil.PostedComments)
+	for _, c := range comments {
+		if c.IsInline() {
+			postedcs.AddPostedComment(c.Path, c.Line, c.Body)
+		}
+	}
+
+	for _, c := range p.postComments {
+		comment := p.buildComment(c)
+		if comment == nil || isPosted(postedcs, comment) {
+			continue
+		}
+		postedcs.AddPostedComment(comment.Path, comment.Line, comment.Body)
+		if err := p.cli.CreatePullRequestComment(ctx, p.owner, p.repo, p.pr, comment); err != nil {
+			return err
+		}
+	}
+	p.postComments = p.postComments[:0]
+
+	return nil
+}
+
+func isPosted(postedcs commentutil.PostedComments, comment *PullRequestComment) bool {
+	for _, body := range postedcs[comment.Path][comment.Line] {
+		if body == comment.Body {
+			return true
+		}
+	}
+	return false
+}
+
+// buildComment returns an inline comment if the result is in diff, otherwise
+// nil.
+func (p *PullRequestCommenter) buildComment(c *reviewdog.Comment) *PullRequestComment {
+	loc := c.Result.Diagnostic.GetLocation()
+	line := int(loc.GetRange().GetStart().GetLine())
+	body := buildCommentBody(c)
+
+	if c.Result.InDiffContext && line > 0 {
+		comment := &PullRequestComment{
+			Body:     body,
+			Path:     loc.GetPath(),
+			Line:     line,
+			LineType: p.lineTypes[loc.GetPath()][line],
+		}
+		if loc.GetOld() {
+			// Bitbucket anchors comments on renamed files to the new path
+			// even if they are on removed lines.
+			comment.LineType = lineTypeRemoved
+			if newPath, ok := p.renames[loc.GetPath()]; ok {
+				comment.Path = newPath
+				comment.SrcPath = loc.GetPath()
+			}
+		} else if c.Result.OldPath != "" && c.Result.OldPath != loc.GetPath() {
+			comment.SrcPath = c.Result.OldPath
+		}
+		if comment.LineType != "" {
+			return comment
+		}
+	}
+	return nil
+}
+
+func buildCommentBody(c *reviewdog.Comment) string {
+	var sb strings.Builder
+	tool := c.Result.Diagnostic.GetSource().GetName()
+	if tool == "" {
+		tool = c.ToolName
+	}
+	if tool != "" {
+		sb.WriteString(fmt.Sprintf("**[%s]** ", tool))
+	}
+	if code := c.Result.Diagnostic.GetCode().GetValue(); code != "" {
+		if url := c.Result.Diagnostic.GetCode().GetUrl(); url != "" {
+			sb.WriteString(fmt.Sprintf("[%s](%s) ", code, url))
+		} else {
+			sb.WriteString(fmt.Sprintf("`%s` ", code))
+		}
+	}
+	sb.WriteString(c.Result.Diagnostic.GetMessage())
+	sb.WriteString(bodySuffix)
+	return sb.String()
+}
+
+// setLineTypes sets line types of lines in the new files of the diff and
+// renamed files.
+func (p *PullRequestCommenter) setLineTypes(ctx context.Context) error {
+	if p.lineTypes != nil {
+		return nil
+	}
+
+	files, err := p.fileDiffs(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.lineTypes = make(map[string]map[int]string, len(files))
+	p.renames = make(map[string]string)
+	for _, file := range files {
+		path := filter.NormalizeDiffPath(file.PathNew, p.Strip())
+		if path == "" {
+			continue
+		}
+		if oldPath := filter.NormalizeDiffPath(file.PathOld, p.Strip()); oldPath != "" && oldPath != path {
+			p.renames[oldPath] = path
+		}
+		p.lineTypes[path] = make(map[int]string)
+		for _, hunk := range file.Hunks {
+			for _, line := range hunk.Lines {
+				switch line.Type {
+				case diff.LineAdded:
+					p.lineTypes[path][line.LnumNew] = lineTypeAdded
+				case diff.LineUnchanged:
+					p.lineTypes[path][line.LnumNew] = lineTypeContext
+				}
+			}
+		}
+	}
+
+	return nil
+}
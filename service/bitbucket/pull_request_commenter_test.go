@@ -0,0 +1,270 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+const testPullRequestDiff = `diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,3 @@
+ line1
++line2
+ line3
+`
+
+func buildPullRequestTestComments() []*reviewdog.Comment {
+	newComment := func(path string, line int32, msg string, inDiff bool) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  path,
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile:    inDiff,
+				InDiffContext: inDiff,
+			},
+		}
+	}
+	return []*reviewdog.Comment{
+		newComment("a.go", 2, "already posted", true),
+		newComment("a.go", 1, "context line", true),
+		newComment("a.go", 2, "added line", true),
+		newComment("b.go", 14, "outside diff", false),
+	}
+}
+
+func postPullRequestTestComments(ctx context.Context, t *testing.T, cli PullRequestClient) {
+	t.Helper()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	p, err := NewPullRequestCommenter(cli, "o", "r", 14)
+	require.NoError(t, err)
+
+	d, err := p.Diff(ctx)
+	require.NoError(t, err)
+	require.Equal(t, testPullRequestDiff, string(d))
+
+	for _, c := range buildPullRequestTestComments() {
+		require.NoError(t, p.Post(ctx, c))
+	}
+	require.NoError(t, p.Flush(ctx))
+	// Comments are not posted twice.
+	require.NoError(t, p.Flush(ctx))
+}
+
+func TestPullRequestCommenter_Cloud(t *testing.T) {
+	var posted []*cloudComment
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/o/r/pullrequests/14/diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testPullRequestDiff)
+	})
+	mux.HandleFunc("/repositories/o/r/pullrequests/14/comments", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+			t.Errorf("unexpected credentials: %q, %q", user, pass)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"values": [{"content": {"raw": %q}, "inline": {"path": "a.go", "to": 2}}],
+					"next": "http://%s%s?page=2"}`,
+					"**[tool]** already posted"+bodySuffix, r.Host, r.URL.Path)
+				return
+			}
+			fmt.Fprintf(w, `{"values": [{"content": {"raw": %q}}, {"content": {"raw": "deleted"}, "deleted": true}]}`,
+				"general comment")
+		case http.MethodPost:
+			var c cloudComment
+			if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+				t.Error(err)
+			}
+			posted = append(posted, &c)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := BuildCloudAPIContext(context.Background(), "user", "pass", "")
+	postPullRequestTestComments(ctx, t, NewCloudPullRequestClientWithURL(nil, ts.URL))
+
+	want := []string{
+		`{"content":{"raw":"**[tool]** context line` + jsonBodySuffix() + `"},"inline":{"path":"a.go","to":1}}`,
+		`{"content":{"raw":"**[tool]** added line` + jsonBodySuffix() + `"},"inline":{"path":"a.go","to":2}}`,
+	}
+	require.Len(t, posted, len(want))
+	for i, c := range posted {
+		b, _ := json.Marshal(c)
+		require.JSONEq(t, want[i], string(b))
+	}
+}
+
+func TestPullRequestCommenter_Server(t *testing.T) {
+	var posted []*serverComment
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/projects/o/repos/r/pull-requests/14.diff", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `diff --git src://a.go dst://a.go
+index 1111111..2222222 100644
+--- src://a.go
++++ dst://a.go
+@@ -1,2 +1,3 @@
+ line1
++line2
+ line3
+`)
+	})
+	mux.HandleFunc("/rest/api/1.0/projects/o/repos/r/pull-requests/14/activities", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected Authorization header: %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("start") == "0" {
+			fmt.Fprintf(w, `{"values": [
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"text": %q},
+				 "commentAnchor": {"path": "a.go", "line": 2, "lineType": "ADDED"}},
+				{"action": "APPROVED"}
+			], "isLastPage": false, "nextPageStart": 2}`, "**[tool]** already posted"+bodySuffix)
+			return
+		}
+		fmt.Fprintf(w, `{"values": [{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"text": %q}}], "isLastPage": true}`,
+			"general comment")
+	})
+	mux.HandleFunc("/rest/api/1.0/projects/o/repos/r/pull-requests/14/comments", func(w http.ResponseWriter, r *http.Request) {
+		var c serverComment
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			t.Error(err)
+		}
+		posted = append(posted, &c)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx, err := BuildServerAPIContext(context.Background(), ts.URL, "", "", "token")
+	require.NoError(t, err)
+	postPullRequestTestComments(ctx, t, NewServerPullRequestClient(ts.URL))
+
+	want := []string{
+		`{"text":"**[tool]** context line` + jsonBodySuffix() + `","anchor":{"path":"a.go","line":1,"lineType":"CONTEXT","fileType":"TO","diffType":"EFFECTIVE"}}`,
+		`{"text":"**[tool]** added line` + jsonBodySuffix() + `","anchor":{"path":"a.go","line":2,"lineType":"ADDED","fileType":"TO","diffType":"EFFECTIVE"}}`,
+	}
+	require.Len(t, posted, len(want))
+	for i, c := range posted {
+		b, _ := json.Marshal(c)
+		require.JSONEq(t, want[i], string(b))
+	}
+}
+
+func jsonBodySuffix() string {
+	b, _ := json.Marshal(bodySuffix)
+	return string(b[1 : len(b)-1])
+}
+
+// fakePullRequestClient is PullRequestClient which returns the diff and
+// records created comments.
+type fakePullRequestClient struct {
+	diff    string
+	created []*PullRequestComment
+}
+
+func (f *fakePullRequestClient) GetPullRequestDiff(_ context.Context, _, _ string, _ int) ([]byte, error) {
+	return []byte(f.diff), nil
+}
+
+func (f *fakePullRequestClient) ListPullRequestComments(_ context.Context, _, _ string, _ int) ([]*PullRequestComment, error) {
+	return nil, nil
+}
+
+func (f *fakePullRequestClient) CreatePullRequestComment(_ context.Context, _, _ string, _ int, comment *PullRequestComment) error {
+	f.created = append(f.created, comment)
+	return nil
+}
+
+func TestPullRequestCommenter_Renamed(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	cli := &fakePullRequestClient{diff: `diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+index 1111111..2222222 100644
+--- a/old.go
++++ b/new.go
+@@ -1,3 +1,3 @@
+ line1
+-line2
++line2 changed
+ line3
+`}
+	p, err := NewPullRequestCommenter(cli, "o", "r", 14)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Strip())
+
+	ctx := context.Background()
+	comments := []*reviewdog.Comment{
+		{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "new.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+					},
+					Message: "added",
+				},
+				InDiffFile:    true,
+				InDiffContext: true,
+				OldPath:       "old.go",
+				OldLine:       2,
+			},
+		},
+		{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "old.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+						Old:   true,
+					},
+					Message: "removed",
+				},
+				InDiffFile:    true,
+				InDiffContext: true,
+				OldPath:       "old.go",
+				OldLine:       2,
+			},
+		},
+	}
+	for _, c := range comments {
+		require.NoError(t, p.Post(ctx, c))
+	}
+	require.NoError(t, p.Flush(ctx))
+
+	require.Equal(t, []*PullRequestComment{
+		{Body: "**[tool]** added" + bodySuffix, Path: "new.go", Line: 2, LineType: lineTypeAdded, SrcPath: "old.go"},
+		{Body: "**[tool]** removed" + bodySuffix, Path: "new.go", Line: 2, LineType: lineTypeRemoved, SrcPath: "old.go"},
+	}, cli.created)
+}
@@ -0,0 +1,99 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/diff"
+)
+
+var _ reviewdog.DiffService = &PullRequestDiff{}
+
+// PullRequestDiff is a diff service for Bitbucket pull requests.
+// It uses the diff of Bitbucket API instead of local git diff, because
+// Bitbucket Pipelines clones the repository without the destination branch.
+// The diff is git diff with rename detection, and paths of renamed files
+// have the old path in "---" line and the new path in "+++" line.
+//
+// Cloud API:
+//  https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/#api-repositories-workspace-repo-slug-pullrequests-pull-request-id-diff-get
+//  GET /2.0/repositories/{workspace}/{repo_slug}/pullrequests/{pull_request_id}/diff
+//
+// Server API:
+//  https://docs.atlassian.com/bitbucket-server/rest/7.21.0/bitbucket-rest.html
+//  GET /rest/api/1.0/projects/{projectKey}/repos/{repositorySlug}/pull-requests/{pullRequestId}.diff
+type PullRequestDiff struct {
+	cli         PullRequestClient
+	owner, repo string
+	pr          int
+
+	muDiff sync.Mutex
+	diff   []byte
+	files  []*diff.FileDiff
+}
+
+// NewPullRequestDiff returns a new PullRequestDiff service.
+func NewPullRequestDiff(cli PullRequestClient, owner, repo string, pr int) *PullRequestDiff {
+	return &PullRequestDiff{
+		cli:   cli,
+		owner: owner,
+		repo:  repo,
+		pr:    pr,
+	}
+}
+
+// Diff returns a diff of the pull request. The diff is fetched only once.
+func (p *PullRequestDiff) Diff(ctx context.Context) ([]byte, error) {
+	p.muDiff.Lock()
+	defer p.muDiff.Unlock()
+
+	if err := p.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	return p.diff, nil
+}
+
+// Strip returns 1 as a strip of git diff. Bitbucket Server diff has "src://"
+// and "dst://" prefixes instead of "a/" and "b/", but they are replaced by
+// ServerPullRequestClient.
+func (p *PullRequestDiff) Strip() int {
+	return 1
+}
+
+// fileDiffs returns parsed diff of the pull request.
+func (p *PullRequestDiff) fileDiffs(ctx context.Context) ([]*diff.FileDiff, error) {
+	p.muDiff.Lock()
+	defer p.muDiff.Unlock()
+
+	if err := p.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	if p.files == nil {
+		files, err := diff.ParseMultiFile(bytes.NewReader(p.diff))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pull request diff: %w", err)
+		}
+		p.files = files
+	}
+
+	return p.files, nil
+}
+
+func (p *PullRequestDiff) fetch(ctx context.Context) error {
+	if p.diff != nil {
+		return nil
+	}
+
+	d, err := p.cli.GetPullRequestDiff(ctx, p.owner, p.repo, p.pr)
+	if err != nil {
+		return err
+	}
+	p.diff = d
+
+	return nil
+}
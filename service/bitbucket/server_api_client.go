@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	insights "github.com/reva2/bitbucket-insights-api"
 )
@@ -43,12 +44,27 @@ func (c *ServerAPIClient) CreateOrUpdateReport(ctx context.Context, req *ReportR
 		return err
 	}
 
-	_, resp, err := c.cli.InsightsApi.
-		UpdateReport(ctx, req.Owner, req.Repository, req.Commit, req.ReportID).
-		Report(c.helper.BuildReport(req)).
-		Execute()
+	// Send the request without the API client because the client doesn't
+	// support report data
+	body, err := buildReportBody(c.helper.BuildReport(req), req.Data)
+	if err != nil {
+		return err
+	}
+
+	config := c.cli.GetConfig()
+	baseURL, err := config.ServerURLWithContext(ctx, "InsightsApiService.UpdateReport")
+	if err != nil {
+		return err
+	}
+	u := fmt.Sprintf("%s/insights/1.0/projects/%s/repos/%s/commits/%s/reports/%s", baseURL,
+		url.PathEscape(req.Owner), url.PathEscape(req.Repository), url.PathEscape(req.Commit), url.PathEscape(req.ReportID))
+	httpReq, err := newRequest(ctx, http.MethodPut, u, body)
+	if err != nil {
+		return err
+	}
+	setServerAuth(httpReq)
 
-	if err := c.checkAPIError(err, resp, http.StatusOK); err != nil {
+	if _, err := doRequest(config.HTTPClient, httpReq, http.StatusOK); err != nil {
 		return fmt.Errorf("failed to create code insights report: %w", err)
 	}
 
@@ -59,7 +75,7 @@ func (c *ServerAPIClient) CreateOrUpdateReport(ctx context.Context, req *ReportR
 func (c *ServerAPIClient) CreateOrUpdateAnnotations(ctx context.Context, req *AnnotationsRequest) error {
 	resp, err := c.cli.InsightsApi.
 		CreateAnnotations(ctx, req.Owner, req.Repository, req.Commit, req.ReportID).
-		AnnotationsList(c.helper.BuildAnnotations(req.Comments)).
+		AnnotationsList(c.helper.BuildAnnotations(req.Comments, req.Type, req.Level)).
 		Execute()
 
 	if err := c.checkAPIError(err, resp, http.StatusNoContent); err != nil {
@@ -0,0 +1,58 @@
+package bitbucket
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerAPIClient_CreateOrUpdateReport(t *testing.T) {
+	var report string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/insights/1.0/projects/o/repos/r/commits/sha/reports/id", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected Authorization header: %q", r.Header.Get("Authorization"))
+		}
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPut:
+			b, _ := ioutil.ReadAll(r.Body)
+			report = string(b)
+			w.Write([]byte(`{}`))
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx, err := BuildServerAPIContext(context.Background(), ts.URL, "", "", "token")
+	require.NoError(t, err)
+
+	err = NewServerAPIClient().CreateOrUpdateReport(ctx, &ReportRequest{
+		Owner:      "o",
+		Repository: "r",
+		Commit:     "sha",
+		ReportID:   "id",
+		Type:       reportTypeSecurity,
+		Title:      "title",
+		Reporter:   reporter,
+		Result:     reportResultFailed,
+		Details:    "details",
+		Data: []ReportData{
+			{Title: "Issues", Type: reportDataTypeNumber, Value: 1},
+		},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"title": "title",
+		"details": "details",
+		"reporter": "reviewdog",
+		"logoUrl": "",
+		"result": "FAIL",
+		"data": [{"title": "Issues", "type": "NUMBER", "value": 1}]
+	}`, report)
+}
@@ -3,6 +3,7 @@ package bitbucket
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 
 	insights "github.com/reva2/bitbucket-insights-api"
@@ -56,3 +57,15 @@ func withServerVariables(ctx context.Context, bbURL string) (context.Context, er
 		},
 	), nil
 }
+
+// setServerAuth sets credentials in context built by BuildServerAPIContext to
+// the request which isn't sent by Code Insights API client
+func setServerAuth(req *http.Request) {
+	ctx := req.Context()
+	if auth, ok := ctx.Value(insights.ContextBasicAuth).(insights.BasicAuth); ok {
+		req.SetBasicAuth(auth.UserName, auth.Password)
+	}
+	if token, ok := ctx.Value(insights.ContextAccessToken).(string); ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	insights "github.com/reva2/bitbucket-insights-api"
 	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 // ServerAPIHelper is collection of utility functions used to build requests
@@ -22,11 +23,12 @@ func (h *ServerAPIHelper) BuildReport(req *ReportRequest) insights.Report {
 	return *data
 }
 
-// BuildAnnotations builds list of Code Insights API annotation objects for specified comments
-func (h *ServerAPIHelper) BuildAnnotations(comments []*reviewdog.Comment) insights.AnnotationsList {
+// BuildAnnotations builds list of Code Insights API annotation objects for specified comments.
+// Code Insights API doesn't support results and end lines of annotations.
+func (h *ServerAPIHelper) BuildAnnotations(comments []*reviewdog.Comment, annotationType, level string) insights.AnnotationsList {
 	annotations := make([]insights.Annotation, len(comments))
 	for idx, comment := range comments {
-		annotations[idx] = h.buildAnnotation(comment)
+		annotations[idx] = h.buildAnnotation(comment, annotationType, level)
 	}
 
 	list := insights.NewAnnotationsList(annotations)
@@ -34,8 +36,8 @@ func (h *ServerAPIHelper) BuildAnnotations(comments []*reviewdog.Comment) insigh
 	return *list
 }
 
-func (h *ServerAPIHelper) buildAnnotation(comment *reviewdog.Comment) insights.Annotation {
-	severity := convertSeverity(comment.Result.Diagnostic.GetSeverity())
+func (h *ServerAPIHelper) buildAnnotation(comment *reviewdog.Comment, annotationType, level string) insights.Annotation {
+	severity := convertSeverity(serviceutil.Severity(comment.Result.Diagnostic, level))
 	if severity == "" {
 		severity = annotationSeverityLow
 	}
@@ -47,7 +49,10 @@ func (h *ServerAPIHelper) buildAnnotation(comment *reviewdog.Comment) insights.A
 		severity,
 	)
 	data.SetExternalId(externalIDFromDiagnostic(comment.Result.Diagnostic))
-	data.SetType(annotationTypeCodeSmell)
+	if annotationType == "" {
+		annotationType = annotationTypeCodeSmell
+	}
+	data.SetType(annotationType)
 
 	if link := comment.Result.Diagnostic.GetCode().GetUrl(); link != "" {
 		data.SetLink(link)
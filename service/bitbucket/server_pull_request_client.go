@@ -0,0 +1,182 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ServerPullRequestClient is client for Bitbucket Server pull request API
+type ServerPullRequestClient struct {
+	cli     *http.Client
+	baseURL string
+}
+
+type serverCommentAnchor struct {
+	Path     string `json:"path"`
+	SrcPath  string `json:"srcPath,omitempty"`
+	Line     int    `json:"line"`
+	LineType string `json:"lineType"`
+	FileType string `json:"fileType"`
+	DiffType string `json:"diffType,omitempty"`
+}
+
+type serverComment struct {
+	Text   string               `json:"text"`
+	Anchor *serverCommentAnchor `json:"anchor,omitempty"`
+}
+
+type serverActivity struct {
+	Action        string               `json:"action"`
+	CommentAction string               `json:"commentAction"`
+	Comment       *serverComment       `json:"comment"`
+	CommentAnchor *serverCommentAnchor `json:"commentAnchor"`
+}
+
+type serverActivitiesPage struct {
+	Values        []*serverActivity `json:"values"`
+	IsLastPage    bool              `json:"isLastPage"`
+	NextPageStart int               `json:"nextPageStart"`
+}
+
+// NewServerPullRequestClient creates client for Bitbucket Server pull request API
+func NewServerPullRequestClient(bbURL string) PullRequestClient {
+	return NewServerPullRequestClientWithClient(nil, bbURL)
+}
+
+// NewServerPullRequestClientWithClient creates client for Bitbucket Server pull request API with specified HTTP client
+func NewServerPullRequestClientWithClient(client *http.Client, bbURL string) PullRequestClient {
+	if client == nil {
+		client = &http.Client{
+			Timeout: httpTimeout,
+		}
+	}
+
+	return &ServerPullRequestClient{
+		cli:     client,
+		baseURL: strings.TrimSuffix(bbURL, "/") + "/rest/api/1.0",
+	}
+}
+
+// GetPullRequestDiff returns diff of pull request in git diff format
+func (c *ServerPullRequestClient) GetPullRequestDiff(ctx context.Context, owner, repo string, pr int) ([]byte, error) {
+	req, err := newRequest(ctx, http.MethodGet, c.pullRequestURL(owner, repo, pr)+".diff", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request diff: %w", err)
+	}
+
+	return normalizeServerDiff(diff), nil
+}
+
+// ListPullRequestComments returns all comments of pull request.
+// Bitbucket Server lists comments of pull request only as activities.
+func (c *ServerPullRequestClient) ListPullRequestComments(ctx context.Context, owner, repo string, pr int) ([]*PullRequestComment, error) {
+	var comments []*PullRequestComment
+	start := 0
+	for {
+		req, err := newRequest(ctx, http.MethodGet,
+			fmt.Sprintf("%s/activities?limit=100&start=%d", c.pullRequestURL(owner, repo, pr), start), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.do(req, http.StatusOK)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull request activities: %w", err)
+		}
+
+		var page serverActivitiesPage
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse pull request activities: %w", err)
+		}
+
+		for _, v := range page.Values {
+			if v.Action != "COMMENTED" || v.CommentAction != "ADDED" || v.Comment == nil {
+				continue
+			}
+			comment := &PullRequestComment{Body: v.Comment.Text}
+			if a := v.CommentAnchor; a != nil {
+				comment.Path = a.Path
+				comment.Line = a.Line
+				comment.LineType = a.LineType
+			}
+			comments = append(comments, comment)
+		}
+
+		if page.IsLastPage || len(page.Values) == 0 {
+			break
+		}
+		start = page.NextPageStart
+	}
+
+	return comments, nil
+}
+
+// CreatePullRequestComment creates comment on pull request
+func (c *ServerPullRequestClient) CreatePullRequestComment(ctx context.Context, owner, repo string, pr int, comment *PullRequestComment) error {
+	body := &serverComment{Text: comment.Body}
+	if comment.IsInline() {
+		body.Anchor = &serverCommentAnchor{
+			Path:     comment.Path,
+			SrcPath:  comment.SrcPath,
+			Line:     comment.Line,
+			LineType: comment.LineType,
+			FileType: "TO",
+			DiffType: "EFFECTIVE",
+		}
+		if comment.LineType == lineTypeRemoved {
+			body.Anchor.FileType = "FROM"
+		}
+	}
+
+	req, err := newRequest(ctx, http.MethodPost, c.pullRequestURL(owner, repo, pr)+"/comments", body)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.do(req, http.StatusCreated); err != nil {
+		return fmt.Errorf("failed to create pull request comment: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ServerPullRequestClient) pullRequestURL(owner, repo string, pr int) string {
+	return fmt.Sprintf("%s/projects/%s/repos/%s/pull-requests/%d",
+		c.baseURL, url.PathEscape(owner), url.PathEscape(repo), pr)
+}
+
+// do sends request with credentials in the context built by BuildServerAPIContext
+func (c *ServerPullRequestClient) do(req *http.Request, expectedCode int) ([]byte, error) {
+	setServerAuth(req)
+
+	return doRequest(c.cli, req, expectedCode)
+}
+
+// normalizeServerDiff replaces "src://" and "dst://" prefixes of paths in raw
+// diff of Bitbucket Server with "a/" and "b/" of git, so that the diff can be
+// stripped like git diff.
+func normalizeServerDiff(diff []byte) []byte {
+	lines := bytes.Split(diff, []byte("\n"))
+	for i, line := range lines {
+		switch {
+		case bytes.HasPrefix(line, []byte("diff --git ")):
+			line = bytes.Replace(line, []byte(" src://"), []byte(" a/"), 1)
+			lines[i] = bytes.Replace(line, []byte(" dst://"), []byte(" b/"), 1)
+		case bytes.HasPrefix(line, []byte("--- src://")):
+			lines[i] = append([]byte("--- a/"), line[len("--- src://"):]...)
+		case bytes.HasPrefix(line, []byte("+++ dst://")):
+			lines[i] = append([]byte("+++ b/"), line[len("+++ dst://"):]...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
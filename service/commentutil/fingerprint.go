@@ -0,0 +1,20 @@
+package commentutil
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+// Fingerprint returns a fingerprint of the result of the given comment to find
+// comments posted for the same result. It doesn't include line numbers so that
+// the result keeps the fingerprint when lines above it are added or removed.
+func Fingerprint(c *reviewdog.Comment) string {
+	d := c.Result.Diagnostic
+	loc := d.GetLocation()
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%t\x00%s\x00%s",
+		c.ToolName, loc.GetPath(), loc.GetOld(), d.GetCode().GetValue(), d.GetMessage())
+	return fmt.Sprintf("%x", h.Sum(nil))[:20]
+}
@@ -0,0 +1,29 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestFingerprint(t *testing.T) {
+	newComment := func(line int32, message string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "a.go", Range: &rdf.Range{
+					Start: &rdf.Position{Line: line},
+				}},
+				Message: message,
+			}},
+		}
+	}
+	if Fingerprint(newComment(1, "msg")) != Fingerprint(newComment(10, "msg")) {
+		t.Error("moved result should have the same fingerprint")
+	}
+	if Fingerprint(newComment(1, "msg")) == Fingerprint(newComment(1, "other")) {
+		t.Error("different results should have different fingerprints")
+	}
+}
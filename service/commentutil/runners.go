@@ -0,0 +1,51 @@
+package commentutil
+
+import "sort"
+
+// Runners tracks Flush of bulk comment services, which is called per runner,
+// so that they report results of all the runners at once. Results of other
+// runners may not be posted yet until all of them are flushed.
+// Runners is not safe for concurrent use.
+type Runners struct {
+	names   map[string]bool
+	flushed int
+}
+
+// NewRunners returns a new Runners for the given runner names. Empty names
+// are ignored.
+func NewRunners(runners []string) *Runners {
+	r := &Runners{names: make(map[string]bool, len(runners))}
+	for _, name := range runners {
+		if name != "" {
+			r.names[name] = true
+		}
+	}
+	return r
+}
+
+// Flush records Flush of a runner, and returns true once all the runners are
+// flushed. It's always true if runners are not given.
+func (r *Runners) Flush() bool {
+	r.flushed++
+	return r.flushed >= len(r.names)
+}
+
+// Len returns the number of the runners.
+func (r *Runners) Len() int {
+	return len(r.names)
+}
+
+// Has returns true if the tool is one of the runners.
+func (r *Runners) Has(tool string) bool {
+	return r.names[tool]
+}
+
+// Names returns sorted names of the runners.
+func (r *Runners) Names() []string {
+	names := make([]string, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,30 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunners(t *testing.T) {
+	r := NewRunners([]string{"b", "", "a"})
+	if diff := cmp.Diff([]string{"a", "b"}, r.Names()); diff != "" {
+		t.Errorf("Names() has diff:\n%s", diff)
+	}
+	if !r.Has("a") || r.Has("") {
+		t.Error("Has() should be true only for the runners")
+	}
+	if r.Flush() {
+		t.Error("Flush() = true before all the runners are flushed")
+	}
+	if !r.Flush() {
+		t.Error("Flush() = false after all the runners are flushed")
+	}
+}
+
+func TestRunners_empty(t *testing.T) {
+	r := NewRunners(nil)
+	if r.Len() != 0 || !r.Flush() {
+		t.Errorf("Len() = %d, Flush() should be true without runners", r.Len())
+	}
+}
@@ -0,0 +1,39 @@
+package commentutil
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+// NonLineBasedSuggestionText returns the whole lines replaced by the suggestion
+// with columns. sourceLines are lines of the file by line number. Columns out
+// of the lines are clamped to the start or end of the lines.
+func NonLineBasedSuggestionText(sourceLines map[int]string, s *rdf.Suggestion) (string, error) {
+	if len(sourceLines) == 0 {
+		return "", errors.New("source lines are not available")
+	}
+	start := s.GetRange().GetStart()
+	end := s.GetRange().GetEnd()
+	startLineContent, err := getSourceLine(sourceLines, int(start.GetLine()))
+	if err != nil {
+		return "", err
+	}
+	endLineContent, err := getSourceLine(sourceLines, int(end.GetLine()))
+	if err != nil {
+		return "", err
+	}
+	return startLineContent[:serviceutil.ColumnOffset(start.GetColumn(), startLineContent)] +
+		s.GetText() +
+		endLineContent[serviceutil.ColumnOffset(end.GetColumn(), endLineContent):], nil
+}
+
+func getSourceLine(sourceLines map[int]string, line int) (string, error) {
+	lineContent, ok := sourceLines[line]
+	if !ok {
+		return "", fmt.Errorf("source line (L=%d) is not available for this suggestion", line)
+	}
+	return lineContent, nil
+}
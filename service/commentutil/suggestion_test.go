@@ -0,0 +1,64 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestNonLineBasedSuggestionText(t *testing.T) {
+	sourceLines := map[int]string{1: "foo := bar", 2: "baz()"}
+	tests := []struct {
+		name  string
+		start *rdf.Position
+		end   *rdf.Position
+		text  string
+		want  string
+	}{
+		{
+			name:  "single line",
+			start: &rdf.Position{Line: 1, Column: 1},
+			end:   &rdf.Position{Line: 1, Column: 4},
+			text:  "qux",
+			want:  "qux := bar",
+		},
+		{
+			name:  "multi lines",
+			start: &rdf.Position{Line: 1, Column: 8},
+			end:   &rdf.Position{Line: 2, Column: 4},
+			text:  "q",
+			want:  "foo := q()",
+		},
+		{
+			name:  "columns out of the lines",
+			start: &rdf.Position{Line: 1, Column: 100},
+			end:   &rdf.Position{Line: 2, Column: 100},
+			text:  "!",
+			want:  "foo := bar!",
+		},
+	}
+	for _, tt := range tests {
+		s := &rdf.Suggestion{Range: &rdf.Range{Start: tt.start, End: tt.end}, Text: tt.text}
+		got, err := NonLineBasedSuggestionText(sourceLines, s)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNonLineBasedSuggestionText_error(t *testing.T) {
+	s := &rdf.Suggestion{Range: &rdf.Range{
+		Start: &rdf.Position{Line: 1, Column: 1},
+		End:   &rdf.Position{Line: 3, Column: 1},
+	}}
+	if _, err := NonLineBasedSuggestionText(nil, s); err == nil {
+		t.Error("got no error without source lines")
+	}
+	if _, err := NonLineBasedSuggestionText(map[int]string{1: "foo"}, s); err == nil {
+		t.Error("got no error without the end line")
+	}
+}
@@ -0,0 +1,68 @@
+package commentutil
+
+import (
+	"sort"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+// ToolComments holds comments per tool for services which report results of
+// all the runners at once. Flush of services is called per runner, so results
+// of other runners may not be posted yet until all of them are flushed.
+// ToolComments is not safe for concurrent use.
+type ToolComments struct {
+	runners  *Runners
+	comments map[string][]*reviewdog.Comment
+}
+
+// NewToolComments returns a new ToolComments for the given runners. Empty
+// runner names are ignored.
+func NewToolComments(runners []string) *ToolComments {
+	return &ToolComments{
+		runners:  NewRunners(runners),
+		comments: make(map[string][]*reviewdog.Comment),
+	}
+}
+
+// Add holds the comment.
+func (t *ToolComments) Add(c *reviewdog.Comment) {
+	t.comments[c.ToolName] = append(t.comments[c.ToolName], c)
+}
+
+// Flush records Flush of a runner, and returns true if the held comments
+// should be reported now: once all the runners are flushed, or on every Flush
+// with comments if runners are not given.
+func (t *ToolComments) Flush() bool {
+	if !t.runners.Flush() {
+		return false
+	}
+	return t.runners.Len() > 0 || len(t.comments) > 0
+}
+
+// Tools returns sorted names of the runners and the tools of the held
+// comments, so that runners without results are included as well.
+func (t *ToolComments) Tools() []string {
+	names := make(map[string]bool)
+	for _, r := range t.runners.Names() {
+		names[r] = true
+	}
+	for tool := range t.comments {
+		names[tool] = true
+	}
+	tools := make([]string, 0, len(names))
+	for tool := range names {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// Comments returns the held comments of the tool.
+func (t *ToolComments) Comments(tool string) []*reviewdog.Comment {
+	return t.comments[tool]
+}
+
+// Reset drops the held comments.
+func (t *ToolComments) Reset() {
+	t.comments = make(map[string][]*reviewdog.Comment)
+}
@@ -0,0 +1,43 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+func TestToolComments(t *testing.T) {
+	tc := NewToolComments([]string{"b", "", "c"})
+	tc.Add(&reviewdog.Comment{ToolName: "a"})
+	tc.Add(&reviewdog.Comment{ToolName: "b"})
+	tc.Add(&reviewdog.Comment{ToolName: "a"})
+	if tc.Flush() {
+		t.Error("Flush() = true before all the runners are flushed")
+	}
+	if !tc.Flush() {
+		t.Error("Flush() = false after all the runners are flushed")
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, tc.Tools()); diff != "" {
+		t.Errorf("Tools() has diff:\n%s", diff)
+	}
+	if got := len(tc.Comments("a")); got != 2 {
+		t.Errorf("got %d comments of a, want 2", got)
+	}
+	tc.Reset()
+	if got := len(tc.Comments("a")); got != 0 {
+		t.Errorf("got %d comments of a after Reset, want 0", got)
+	}
+}
+
+func TestToolComments_withoutRunners(t *testing.T) {
+	tc := NewToolComments(nil)
+	if tc.Flush() {
+		t.Error("Flush() = true without comments")
+	}
+	tc.Add(&reviewdog.Comment{ToolName: "a"})
+	if !tc.Flush() {
+		t.Error("Flush() = false with comments")
+	}
+}
@@ -4,47 +4,98 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
-
-	"golang.org/x/build/gerrit"
+	"time"
 
 	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
 	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 var _ reviewdog.CommentService = &ChangeReviewCommenter{}
 
-// ChangeReviewCommenter is a comment service for Gerrit Change Review
+// ChangeReviewCommenter is a comment service for Gerrit Change Review.
+// It posts results as robot comments with fix suggestions.
 // API:
-// 	https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#set-review
-// 	POST /changes/{change-id}/revisions/{revision-id}/review
+//
+//	https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#set-review
+//	POST /changes/{change-id}/revisions/{revision-id}/review
 type ChangeReviewCommenter struct {
-	cli        *gerrit.Client
+	cli        *Client
 	changeID   string
 	revisionID string
+	runID      string
+	opt        ReviewOption
 
 	muComments   sync.Mutex
 	postComments []*reviewdog.Comment
 
+	// runners is the set of runners whose results are reported. The summary
+	// and the vote are posted after all the runners are flushed.
+	runners *commentutil.Runners
+	// counts holds the number of results per tool and severity. Results are
+	// counted once when they're posted to the service.
+	counts *serviceutil.ToolSeverityCounts
+	// posted holds the number of robot comments which are already posted by
+	// their keys. It's initialized on the first Flush.
+	posted map[string]int
+	// seen holds results which are already posted or skipped in this run.
+	seen map[string]bool
+
 	// wd is working directory relative to root of repository.
 	wd string
 }
 
+// ReviewOption is an option of reviews posted by ChangeReviewCommenter.
+type ReviewOption struct {
+	// Label is a label to vote (e.g. Verified). It doesn't vote if empty.
+	Label string
+	// Approve is a vote when there are no results at or above Threshold.
+	Approve int
+	// Reject is a vote when there are results at or above Threshold.
+	Reject int
+	// Threshold is the lowest severity of results which reject changes.
+	Threshold rdf.Severity
+
+	// Level is used as the severity of results which don't have severity.
+	Level string
+
+	// Notify is whom to notify about reviews (NONE, OWNER, OWNER_REVIEWERS,
+	// or ALL). Gerrit notifies all by default.
+	Notify string
+
+	// SkipUnresolved doesn't post results which still have unresolved
+	// comments on previous patch sets, since Gerrit shows unresolved comments
+	// on later patch sets as well.
+	SkipUnresolved bool
+}
+
 // NewChangeReviewCommenter returns a new NewChangeReviewCommenter service.
+// runners are names of runners whose results are reported, and the summary
+// message and the vote are posted after results of all the runners are
+// flushed.
 // ChangeReviewCommenter service needs git command in $PATH.
-func NewChangeReviewCommenter(cli *gerrit.Client, changeID, revisionID string) (*ChangeReviewCommenter, error) {
+func NewChangeReviewCommenter(cli *Client, changeID, revisionID string, runners []string, opt ReviewOption) (*ChangeReviewCommenter, error) {
 	workDir, err := serviceutil.GitRelWorkdir()
 	if err != nil {
 		return nil, fmt.Errorf("ChangeReviewCommenter needs 'git' command: %w", err)
 	}
 
-	return &ChangeReviewCommenter{
+	g := &ChangeReviewCommenter{
 		cli:          cli,
 		changeID:     changeID,
 		revisionID:   revisionID,
+		runID:        strconv.FormatInt(time.Now().Unix(), 10),
+		opt:          opt,
 		postComments: []*reviewdog.Comment{},
+		runners:      commentutil.NewRunners(runners),
 		wd:           workDir,
-	}, nil
+	}
+	g.counts = serviceutil.NewToolSeverityCounts(g.runners.Names(), opt.Level)
+	return g, nil
 }
 
 // Post accepts a comment and holds it. Flush method actually posts comments to Gerrit
@@ -53,6 +104,7 @@ func (g *ChangeReviewCommenter) Post(_ context.Context, c *reviewdog.Comment) er
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
 	g.postComments = append(g.postComments, c)
+	g.counts.Add(c.ToolName, c.Result.Diagnostic)
 	return nil
 }
 
@@ -61,24 +113,214 @@ func (g *ChangeReviewCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
 
-	return g.postAllComments(ctx)
+	allFlushed := g.runners.Flush()
+	if g.posted == nil {
+		if err := g.setPostedComments(ctx); err != nil {
+			return err
+		}
+	}
+	return g.postAllComments(ctx, allFlushed)
 }
 
-func (g *ChangeReviewCommenter) postAllComments(ctx context.Context) error {
-	review := gerrit.ReviewInput{
-		Comments: map[string][]gerrit.CommentInput{},
+// postAllComments posts the held comments. It posts the summary and the vote
+// as well if all the runners are flushed.
+func (g *ChangeReviewCommenter) postAllComments(ctx context.Context, allFlushed bool) error {
+	review := reviewInput{
+		RobotComments: map[string][]robotCommentInput{},
+		Notify:        g.opt.Notify,
 	}
 	for _, c := range g.postComments {
 		if !c.Result.InDiffFile {
 			continue
 		}
-		loc := c.Result.Diagnostic.GetLocation()
-		path := loc.GetPath()
-		review.Comments[path] = append(review.Comments[path], gerrit.CommentInput{
+		path := c.Result.Diagnostic.GetLocation().GetPath()
+		comment := g.buildRobotComment(c)
+		key := postedKey(path, comment.RobotID, comment.Message)
+		// Skip duplicated results.
+		result := fmt.Sprintf("%s\x00%d", key, comment.Line)
+		if g.seen[result] {
+			continue
+		}
+		g.seen[result] = true
+		// Each posted comment matches one result with the same key.
+		if g.posted[key] > 0 {
+			g.posted[key]--
+			continue
+		}
+		review.RobotComments[path] = append(review.RobotComments[path], comment)
+	}
+	if allFlushed {
+		review.Message = g.summary()
+		if g.opt.Label != "" {
+			review.Labels = map[string]int{g.opt.Label: g.vote()}
+		}
+	}
+
+	if len(review.RobotComments) == 0 && review.Message == "" {
+		// All the held comments are already posted or not postable.
+		g.postComments = g.postComments[:0]
+		return nil
+	}
+
+	if err := g.cli.do(ctx, "POST", fmt.Sprintf("/changes/%s/revisions/%s/review", g.changeID, g.revisionID), review, nil); err != nil {
+		return err
+	}
+	g.postComments = g.postComments[:0]
+	return nil
+}
+
+// summary returns a review message with the number of results per tool.
+func (g *ChangeReviewCommenter) summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "reviewdog: %d finding(s)\n", g.counts.Total())
+	for _, tool := range g.counts.Tools() {
+		counts := g.counts.Get(tool).String()
+		if counts == "" {
+			counts = "no findings"
+		}
+		name := tool
+		if name == "" {
+			name = "reviewdog"
+		}
+		fmt.Fprintf(&sb, "\n* %s: %s", name, counts)
+	}
+	return sb.String()
+}
+
+// vote returns Reject if there are results at or above the threshold,
+// otherwise Approve.
+func (g *ChangeReviewCommenter) vote() int {
+	for _, tool := range g.counts.Tools() {
+		if g.counts.Get(tool).AtOrAbove(g.opt.Threshold) {
+			return g.opt.Reject
+		}
+	}
+	return g.opt.Approve
+}
+
+func (g *ChangeReviewCommenter) buildRobotComment(c *reviewdog.Comment) robotCommentInput {
+	d := c.Result.Diagnostic
+	loc := d.GetLocation()
+	comment := robotCommentInput{
+		commentInput: commentInput{
 			Line:    int(loc.GetRange().GetStart().GetLine()),
-			Message: c.Result.Diagnostic.GetMessage(),
-		})
+			Message: d.GetMessage(),
+		},
+		RobotID:    robotID(c),
+		RobotRunID: g.runID,
+		URL:        d.GetCode().GetUrl(),
+	}
+	// Only errors need to be resolved before submitting changes.
+	comment.Unresolved = serviceutil.Severity(d, g.opt.Level) == rdf.Severity_ERROR
+	if loc.GetOld() {
+		comment.Side = "PARENT"
+	}
+	if r := commentRangeOf(loc.GetRange(), c.Result.SourceLines); r != nil {
+		comment.Line = r.EndLine
+		comment.Range = r
+	}
+	if code := d.GetCode().GetValue(); code != "" {
+		comment.Properties = map[string]string{"code": code}
+	}
+	if d.GetSeverity() != rdf.Severity_UNKNOWN_SEVERITY {
+		if comment.Properties == nil {
+			comment.Properties = map[string]string{}
+		}
+		comment.Properties["severity"] = d.GetSeverity().String()
+	}
+	if !loc.GetOld() {
+		for _, s := range d.GetSuggestions() {
+			if fix := buildFixSuggestion(comment.RobotID, loc.GetPath(), s, c.Result.SourceLines); fix != nil {
+				comment.FixSuggestions = append(comment.FixSuggestions, *fix)
+			}
+		}
+	}
+	return comment
+}
+
+func robotID(c *reviewdog.Comment) string {
+	if c.ToolName != "" {
+		return c.ToolName
+	}
+	if name := c.Result.Diagnostic.GetSource().GetName(); name != "" {
+		return name
+	}
+	return "reviewdog"
+}
+
+// commentRangeOf returns a comment range of multi-line or column based
+// diagnostics. It returns nil for line based diagnostics.
+func commentRangeOf(r *rdf.Range, sourceLines map[int]string) *commentRange {
+	start, end := r.GetStart(), r.GetEnd()
+	if start.GetLine() == 0 || end.GetLine() == 0 {
+		return nil
+	}
+	cr := &commentRange{
+		StartLine:      int(start.GetLine()),
+		StartCharacter: character(start.GetColumn()),
+		EndLine:        int(end.GetLine()),
+	}
+	switch {
+	case end.GetColumn() > 0:
+		cr.EndCharacter = character(end.GetColumn())
+	case end.GetLine() == start.GetLine() && start.GetColumn() == 0:
+		// Line based diagnostic.
+		return nil
+	default:
+		// The range includes the whole end line.
+		line, ok := sourceLines[int(end.GetLine())]
+		if !ok {
+			return nil
+		}
+		cr.EndCharacter = len(line)
 	}
+	return cr
+}
 
-	return g.cli.SetReview(ctx, g.changeID, g.revisionID, review)
+// buildFixSuggestion returns a fix suggestion from the given suggestion. It
+// returns nil if the suggestion range is not available.
+func buildFixSuggestion(robotID, path string, s *rdf.Suggestion, sourceLines map[int]string) *fixSuggestionInfo {
+	start, end := s.GetRange().GetStart(), s.GetRange().GetEnd()
+	if start.GetLine() == 0 {
+		return nil
+	}
+	if end.GetLine() == 0 {
+		end = start
+	}
+	r := commentRange{
+		StartLine:      int(start.GetLine()),
+		StartCharacter: character(start.GetColumn()),
+		EndLine:        int(end.GetLine()),
+		EndCharacter:   character(end.GetColumn()),
+	}
+	replacement := s.GetText()
+	if start.GetColumn() == 0 && end.GetColumn() == 0 {
+		// Line based suggestion replaces the whole lines.
+		line, ok := sourceLines[int(end.GetLine())]
+		if ok && replacement != "" {
+			r.EndCharacter = len(line)
+		} else {
+			// Delete the lines including the last newline.
+			r.EndLine++
+			if replacement != "" {
+				replacement += "\n"
+			}
+		}
+	}
+	return &fixSuggestionInfo{
+		Description: "Fix suggested by " + robotID,
+		Replacements: []fixReplacementInfo{{
+			Path:        path,
+			Range:       r,
+			Replacement: replacement,
+		}},
+	}
+}
+
+// character converts 1-based column to 0-based character offset of Gerrit.
+func character(column int32) int {
+	if column <= 0 {
+		return 0
+	}
+	return int(column) - 1
 }
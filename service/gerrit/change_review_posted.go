@@ -0,0 +1,105 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+)
+
+// commentInfo is CommentInfo or RobotCommentInfo of Gerrit REST API.
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#comment-info
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#robot-comment-info
+type commentInfo struct {
+	ID         string `json:"id"`
+	PatchSet   int    `json:"patch_set"`
+	Line       int    `json:"line"`
+	Message    string `json:"message"`
+	InReplyTo  string `json:"in_reply_to"`
+	Unresolved bool   `json:"unresolved"`
+	Updated    string `json:"updated"`
+	RobotID    string `json:"robot_id"`
+}
+
+// postedKey returns a key to match results with posted robot comments. It
+// doesn't include line numbers so that results still match their comments
+// on previous patch sets after lines above them are changed.
+func postedKey(path, robotID, message string) string {
+	return fmt.Sprintf("%s\x00%s\x00%s", path, robotID, message)
+}
+
+// setPostedComments counts robot comments which are already posted to the
+// current revision by their keys. If SkipUnresolved option is enabled, it also
+// counts unresolved robot comments on previous patch sets, which Gerrit shows
+// on later patch sets.
+func (g *ChangeReviewCommenter) setPostedComments(ctx context.Context) error {
+	g.posted = make(map[string]int)
+	g.seen = make(map[string]bool)
+	var current map[string][]commentInfo
+	if err := g.cli.do(ctx, "GET", fmt.Sprintf("/changes/%s/revisions/%s/robotcomments", g.changeID, g.revisionID), nil, &current); err != nil {
+		return fmt.Errorf("failed to list robot comments: %w", err)
+	}
+	currentIDs := make(map[string]bool)
+	for path, cs := range current {
+		for _, c := range cs {
+			currentIDs[c.ID] = true
+			g.posted[postedKey(path, c.RobotID, c.Message)]++
+		}
+	}
+	if !g.opt.SkipUnresolved {
+		return nil
+	}
+
+	var robotComments, comments map[string][]commentInfo
+	if err := g.cli.do(ctx, "GET", fmt.Sprintf("/changes/%s/robotcomments", g.changeID), nil, &robotComments); err != nil {
+		return fmt.Errorf("failed to list robot comments: %w", err)
+	}
+	if err := g.cli.do(ctx, "GET", fmt.Sprintf("/changes/%s/comments", g.changeID), nil, &comments); err != nil {
+		return fmt.Errorf("failed to list comments: %w", err)
+	}
+	unresolved := unresolvedThreads(robotComments, comments)
+	for path, cs := range robotComments {
+		for _, c := range cs {
+			if unresolved[c.ID] && !currentIDs[c.ID] {
+				g.posted[postedKey(path, c.RobotID, c.Message)]++
+			}
+		}
+	}
+	return nil
+}
+
+// unresolvedThreads returns IDs of robot comments whose threads are
+// unresolved. The state of a thread is the state of its last comment.
+func unresolvedThreads(robotComments, comments map[string][]commentInfo) map[string]bool {
+	parent := make(map[string]string)
+	last := make(map[string]commentInfo)
+	for _, cs := range robotComments {
+		for _, c := range cs {
+			last[c.ID] = c
+		}
+	}
+	var replies []commentInfo
+	for _, cs := range comments {
+		for _, c := range cs {
+			if c.InReplyTo != "" {
+				parent[c.ID] = c.InReplyTo
+				replies = append(replies, c)
+			}
+		}
+	}
+	for _, c := range replies {
+		root := c.InReplyTo
+		// Guard against cycles in broken data.
+		for i := 0; i < len(parent) && parent[root] != ""; i++ {
+			root = parent[root]
+		}
+		if l, ok := last[root]; ok && c.Updated >= l.Updated {
+			last[root] = c
+		}
+	}
+	unresolved := make(map[string]bool, len(last))
+	for id, c := range last {
+		if c.Unresolved {
+			unresolved[id] = true
+		}
+	}
+	return unresolved
+}
@@ -10,7 +10,6 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	"golang.org/x/build/gerrit"
 
 	"github.com/reviewdog/reviewdog"
 	"github.com/reviewdog/reviewdog/filter"
@@ -59,6 +58,44 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 			InDiffFile: true,
 		},
 	}
+	commentWithSuggestion := &reviewdog.Comment{
+		ToolName: "tool",
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "file2.go",
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 20, Column: 3},
+						End:   &rdf.Position{Line: 21, Column: 5},
+					},
+				},
+				Message:  "comment with suggestions",
+				Severity: rdf.Severity_ERROR,
+				Code:     &rdf.Code{Value: "rule", Url: "https://example.com/rule"},
+				Suggestions: []*rdf.Suggestion{
+					{
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 20, Column: 3},
+							End:   &rdf.Position{Line: 21, Column: 5},
+						},
+						Text: "fixed",
+					},
+					{
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 22},
+							End:   &rdf.Position{Line: 23},
+						},
+						Text: "line22-fixed\nline23-fixed",
+					},
+					{
+						Range: &rdf.Range{Start: &rdf.Position{Line: 24}},
+					},
+				},
+			},
+			InDiffFile:  true,
+			SourceLines: map[int]string{20: "line20", 21: "line21", 22: "line22", 23: "line23", 24: "line24"},
+		},
+	}
 	commentOutsideDiff := &reviewdog.Comment{
 		Result: &filter.FilteredDiagnostic{
 			Diagnostic: &rdf.Diagnostic{
@@ -77,33 +114,84 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 	comments := []*reviewdog.Comment{
 		newComment1,
 		newComment2,
+		commentWithSuggestion,
 		commentOutsideDiff,
 	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(`/a/changes/testChangeID/revisions/testRevisionID/robotcomments`, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, ")]}'\n{}")
+	})
+	mux.HandleFunc(`/a/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			got := new(gerrit.ReviewInput)
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				t.Errorf("request is not authenticated: %v", r.Header)
+			}
+			got := new(reviewInput)
 			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
 				t.Error(err)
 			}
-
-			if want := len(comments) - 1; len(got.Comments) != want {
-				t.Errorf("got %d comments, want %d", len(got.Comments), want)
+			for _, cs := range got.RobotComments {
+				for i := range cs {
+					if cs[i].RobotRunID == "" {
+						t.Errorf("robot_run_id is empty: %+v", cs[i])
+					}
+					cs[i].RobotRunID = ""
+				}
 			}
-
-			line1 := int(newComment1.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
-			want := []gerrit.CommentInput{{
-				Line: line1, Message: newComment1.Result.Diagnostic.GetMessage()}}
-			if diff := cmp.Diff(got.Comments["file.go"], want); diff != "" {
-				t.Error(diff)
+			want := map[string][]robotCommentInput{
+				"file.go": {
+					{
+						commentInput: commentInput{Line: newLnum1, Message: "new comment"},
+						RobotID:      "reviewdog",
+					},
+				},
+				"file2.go": {
+					{
+						commentInput: commentInput{Line: newLnum2, Message: "new comment 2"},
+						RobotID:      "reviewdog",
+					},
+					{
+						commentInput: commentInput{
+							Line:       21,
+							Range:      &commentRange{StartLine: 20, StartCharacter: 2, EndLine: 21, EndCharacter: 4},
+							Message:    "comment with suggestions",
+							Unresolved: true,
+						},
+						RobotID:    "tool",
+						URL:        "https://example.com/rule",
+						Properties: map[string]string{"code": "rule", "severity": "ERROR"},
+						FixSuggestions: []fixSuggestionInfo{
+							{
+								Description: "Fix suggested by tool",
+								Replacements: []fixReplacementInfo{{
+									Path:        "file2.go",
+									Range:       commentRange{StartLine: 20, StartCharacter: 2, EndLine: 21, EndCharacter: 4},
+									Replacement: "fixed",
+								}},
+							},
+							{
+								Description: "Fix suggested by tool",
+								Replacements: []fixReplacementInfo{{
+									Path:        "file2.go",
+									Range:       commentRange{StartLine: 22, StartCharacter: 0, EndLine: 23, EndCharacter: 6},
+									Replacement: "line22-fixed\nline23-fixed",
+								}},
+							},
+							{
+								Description: "Fix suggested by tool",
+								Replacements: []fixReplacementInfo{{
+									Path:        "file2.go",
+									Range:       commentRange{StartLine: 24, StartCharacter: 0, EndLine: 25, EndCharacter: 0},
+									Replacement: "",
+								}},
+							},
+						},
+					},
+				},
 			}
-
-			line2 := int(newComment2.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
-			want = []gerrit.CommentInput{{
-				Line: line2, Message: newComment2.Result.Diagnostic.GetMessage()}}
-			if diff := cmp.Diff(got.Comments["file2.go"], want); diff != "" {
+			if diff := cmp.Diff(got.RobotComments, want, cmp.AllowUnexported(robotCommentInput{})); diff != "" {
 				t.Error(diff)
 			}
 
@@ -116,9 +204,9 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
-	cli := gerrit.NewClient(ts.URL, gerrit.NoAuth)
+	cli := NewClient(nil, ts.URL, BasicAuth("user", "pass"))
 
-	g, err := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID")
+	g, err := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID", nil, ReviewOption{Level: "warning"})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -133,3 +221,288 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 		t.Errorf("%v", err)
 	}
 }
+
+func TestChangeReviewCommenter_vote(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(tool string, severity rdf.Severity) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: tool,
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+					},
+					Message:  "message",
+					Severity: severity,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opt      ReviewOption
+		comments []*reviewdog.Comment
+		want     reviewInput
+	}{
+		{
+			name: "clean",
+			opt:  ReviewOption{Label: "Code-Style", Approve: 1, Reject: -1, Threshold: rdf.Severity_ERROR, Notify: "OWNER"},
+			want: reviewInput{
+				Message: "reviewdog: 0 finding(s)\n\n* tool-a: no findings\n* tool-b: no findings",
+				Labels:  map[string]int{"Code-Style": 1},
+				Notify:  "OWNER",
+			},
+		},
+		{
+			name: "warnings under threshold",
+			opt:  ReviewOption{Label: "Code-Style", Approve: 1, Reject: -1, Threshold: rdf.Severity_ERROR},
+			comments: []*reviewdog.Comment{
+				newComment("tool-b", rdf.Severity_WARNING),
+				newComment("tool-b", rdf.Severity_INFO),
+			},
+			want: reviewInput{
+				Message: "reviewdog: 2 finding(s)\n\n* tool-a: no findings\n* tool-b: 1 warning, 1 info",
+				Labels:  map[string]int{"Code-Style": 1},
+			},
+		},
+		{
+			name: "errors",
+			opt:  ReviewOption{Label: "Verified", Approve: 1, Reject: -1, Threshold: rdf.Severity_ERROR, Level: "error"},
+			comments: []*reviewdog.Comment{
+				newComment("tool-b", rdf.Severity_WARNING),
+				newComment("tool-b", rdf.Severity_UNKNOWN_SEVERITY), // Use Level.
+			},
+			want: reviewInput{
+				Message: "reviewdog: 2 finding(s)\n\n* tool-a: no findings\n* tool-b: 1 error, 1 warning",
+				Labels:  map[string]int{"Verified": -1},
+			},
+		},
+		{
+			name: "warning threshold without label",
+			opt:  ReviewOption{Threshold: rdf.Severity_WARNING},
+			comments: []*reviewdog.Comment{
+				newComment("tool-b", rdf.Severity_WARNING),
+			},
+			want: reviewInput{
+				Message: "reviewdog: 1 finding(s)\n\n* tool-a: no findings\n* tool-b: 1 warning",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reviews []*reviewInput
+			mux := http.NewServeMux()
+			mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/robotcomments`, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, ")]}'\n{}")
+			})
+			mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+				got := new(reviewInput)
+				if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+					t.Error(err)
+				}
+				got.RobotComments = nil
+				reviews = append(reviews, got)
+				fmt.Fprintf(w, ")]}\n{}")
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			g, err := NewChangeReviewCommenter(NewClient(nil, ts.URL, NoAuth), "testChangeID", "testRevisionID", []string{"tool-a", "tool-b"}, tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			// Flush per runner like project mode. The summary and the vote
+			// should be posted after all the runners are flushed.
+			if err := g.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.comments {
+				if err := g.Post(ctx, c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := g.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			want := []*reviewInput{&tt.want}
+			if diff := cmp.Diff(reviews, want); diff != "" {
+				t.Errorf("reviews diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestChangeReviewCommenter_Flush_count_once(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	var reviews []*reviewInput
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/robotcomments`, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, ")]}'\n{}")
+	})
+	mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+		got := new(reviewInput)
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Error(err)
+		}
+		reviews = append(reviews, got)
+		fmt.Fprintf(w, ")]}\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	opt := ReviewOption{Label: "Verified", Approve: 1, Reject: -1, Threshold: rdf.Severity_ERROR, Level: "warning"}
+	g, err := NewChangeReviewCommenter(NewClient(nil, ts.URL, NoAuth), "testChangeID", "testRevisionID", []string{"a", "b", "c"}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// A result outside the diff isn't posted, so the first Flush posts nothing.
+	outside := &reviewdog.Comment{
+		ToolName: "a",
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "file.go", Range: &rdf.Range{Start: &rdf.Position{Line: 1}}},
+				Message:  "outside",
+			},
+		},
+	}
+	if err := g.Post(ctx, outside); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := g.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []*reviewInput{{
+		Message: "reviewdog: 1 finding(s)\n\n* a: 1 warning\n* b: no findings\n* c: no findings",
+		Labels:  map[string]int{"Verified": 1},
+	}}
+	if diff := cmp.Diff(reviews, want); diff != "" {
+		t.Errorf("reviews diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestChangeReviewCommenter_posted(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	comments := []*reviewdog.Comment{
+		newComment(1, "posted to current revision"),
+		// Lines above the result have been changed since the previous patch set.
+		newComment(12, "unresolved on previous patch set"),
+		newComment(3, "resolved on previous patch set"),
+		newComment(4, "new"),
+		newComment(4, "new"), // duplicated result.
+		newComment(5, "posted to current revision"),
+	}
+
+	for _, tt := range []struct {
+		skipUnresolved bool
+		want           []string
+	}{
+		{
+			skipUnresolved: false,
+			want:           []string{"unresolved on previous patch set", "resolved on previous patch set", "new", "posted to current revision"},
+		},
+		{
+			skipUnresolved: true,
+			want:           []string{"resolved on previous patch set", "new", "posted to current revision"},
+		},
+	} {
+		var got []string
+		mux := http.NewServeMux()
+		mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/robotcomments`, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, ")]}'\n")
+			json.NewEncoder(w).Encode(map[string][]commentInfo{
+				"file.go": {{ID: "c1", PatchSet: 2, Line: 1, Message: "posted to current revision", RobotID: "tool"}},
+			})
+		})
+		mux.HandleFunc(`/changes/testChangeID/robotcomments`, func(w http.ResponseWriter, r *http.Request) {
+			if !tt.skipUnresolved {
+				t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+			}
+			fmt.Fprintf(w, ")]}'\n")
+			json.NewEncoder(w).Encode(map[string][]commentInfo{
+				"file.go": {
+					{ID: "c1", PatchSet: 2, Line: 1, Message: "posted to current revision", RobotID: "tool"},
+					{ID: "c2", PatchSet: 1, Line: 2, Message: "unresolved on previous patch set", RobotID: "tool", Unresolved: true, Updated: "2022-01-01 00:00:00.000000000"},
+					{ID: "c3", PatchSet: 1, Line: 3, Message: "resolved on previous patch set", RobotID: "tool", Unresolved: true, Updated: "2022-01-01 00:00:00.000000000"},
+				},
+			})
+		})
+		mux.HandleFunc(`/changes/testChangeID/comments`, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, ")]}'\n")
+			json.NewEncoder(w).Encode(map[string][]commentInfo{
+				"file.go": {
+					{ID: "r1", PatchSet: 1, Line: 3, Message: "Done", InReplyTo: "c3", Unresolved: true, Updated: "2022-01-02 00:00:00.000000000"},
+					{ID: "r2", PatchSet: 1, Line: 3, Message: "Ack", InReplyTo: "r1", Unresolved: false, Updated: "2022-01-03 00:00:00.000000000"},
+				},
+			})
+		})
+		mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+			review := new(reviewInput)
+			if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+				t.Error(err)
+			}
+			for _, c := range review.RobotComments["file.go"] {
+				got = append(got, c.Message)
+			}
+			fmt.Fprintf(w, ")]}'\n{}")
+		})
+		ts := httptest.NewServer(mux)
+
+		g, err := NewChangeReviewCommenter(NewClient(nil, ts.URL, NoAuth), "testChangeID", "testRevisionID", nil, ReviewOption{SkipUnresolved: tt.skipUnresolved})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+		for _, c := range comments {
+			if err := g.Post(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := g.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		// Flush again should not post the same comments.
+		for _, c := range comments {
+			if err := g.Post(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := g.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("skipUnresolved=%v: posted comments diff: (-got +want)\n%s", tt.skipUnresolved, diff)
+		}
+		ts.Close()
+	}
+}
@@ -0,0 +1,186 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is a client for Gerrit REST APIs which golang.org/x/build/gerrit
+// doesn't support, such as robot comments.
+//
+// API:
+//	https://gerrit-review.googlesource.com/Documentation/rest-api.html
+type Client struct {
+	cli     *http.Client
+	baseURL string
+	auth    Auth
+}
+
+// Auth is an authentication method of Gerrit REST APIs.
+type Auth interface {
+	// setAuth sets credentials to the request to the given URL.
+	setAuth(req *http.Request, u *url.URL)
+}
+
+// NoAuth makes requests unauthenticated.
+var NoAuth Auth = noAuth{}
+
+type noAuth struct{}
+
+func (noAuth) setAuth(*http.Request, *url.URL) {}
+
+// BasicAuth sends a username and HTTP password.
+func BasicAuth(username, password string) Auth {
+	return basicAuth{username: username, password: password}
+}
+
+type basicAuth struct {
+	username, password string
+}
+
+func (a basicAuth) setAuth(req *http.Request, _ *url.URL) {
+	req.SetBasicAuth(a.username, a.password)
+}
+
+// GitCookieFileAuth sends cookies in the gitcookies file (Netscape cookie
+// file format) which match the Gerrit URL.
+func GitCookieFileAuth(file string) (Auth, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git cookie file: %w", err)
+	}
+	return &cookieAuth{jar: parseGitCookies(string(b))}, nil
+}
+
+type cookieAuth struct {
+	jar *cookiejar.Jar
+}
+
+func (a *cookieAuth) setAuth(req *http.Request, u *url.URL) {
+	for _, c := range a.jar.Cookies(u) {
+		req.AddCookie(c)
+	}
+}
+
+// parseGitCookies parses lines of "domain, flag, path, secure, expiration,
+// name, value" separated by TAB.
+func parseGitCookies(data string) *cookiejar.Jar {
+	jar, _ := cookiejar.New(nil)
+	for _, line := range strings.Split(data, "\n") {
+		f := strings.Split(strings.TrimSpace(line), "\t")
+		if len(f) < 7 {
+			continue
+		}
+		expires, err := strconv.ParseInt(f[4], 10, 64)
+		if err != nil {
+			continue
+		}
+		c := &http.Cookie{
+			Domain:  f[0],
+			Path:    f[2],
+			Secure:  f[3] == "TRUE",
+			Expires: time.Unix(expires, 0),
+			Name:    f[5],
+			Value:   f[6],
+		}
+		jar.SetCookies(&url.URL{Scheme: "http", Host: strings.TrimPrefix(c.Domain, "."), Path: c.Path}, []*http.Cookie{c})
+	}
+	return jar
+}
+
+// UnexpectedResponseError is returned when Gerrit REST API returns unexpected
+// status code.
+type UnexpectedResponseError struct {
+	Code int
+	Body []byte
+}
+
+func (e UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("gerrit api: unexpected response code %d: %s", e.Code, e.Body)
+}
+
+// NewClient returns a new Client for the Gerrit server at baseURL (e.g.
+// https://gerrit-review.googlesource.com).
+func NewClient(cli *http.Client, baseURL string, auth Auth) *Client {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	if auth == nil {
+		auth = NoAuth
+	}
+	return &Client{cli: cli, baseURL: strings.TrimSuffix(baseURL, "/"), auth: auth}
+}
+
+// do sends a request to the path (e.g. /changes/{change-id}/comments) with
+// JSON encoded body if it's not nil, and decodes the response into out if
+// it's not nil.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	// Authenticated requests are sent to the endpoints prefixed by "/a".
+	// https://gerrit-review.googlesource.com/Documentation/rest-api.html#authentication
+	prefix := "/a"
+	if _, ok := c.auth.(noAuth); ok {
+		prefix = ""
+	}
+	u, err := url.Parse(c.baseURL + prefix + path)
+	if err != nil {
+		return err
+	}
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.auth.setAuth(req, u)
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return UnexpectedResponseError{Code: resp.StatusCode, Body: b}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(stripXSSIPrefix(b), out)
+}
+
+// stripXSSIPrefix strips the magic prefix line like ")]}'" of JSON responses,
+// which prevents XSSI attacks.
+// https://gerrit-review.googlesource.com/Documentation/rest-api.html#output
+func stripXSSIPrefix(b []byte) []byte {
+	if !bytes.HasPrefix(b, []byte(")]}")) {
+		return b
+	}
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		return b[i+1:]
+	}
+	return nil
+}
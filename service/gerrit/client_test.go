@@ -0,0 +1,86 @@
+package gerrit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClient_do(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a/changes/c/comments", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("unexpected auth: %q %q", user, pass)
+		}
+		fmt.Fprint(w, ")]}'\n{\"x\": 1}")
+	})
+	mux.HandleFunc("/changes/c/comments", func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Error("unauthenticated request has basic auth")
+		}
+		fmt.Fprint(w, `{"x": 2}`)
+	})
+	mux.HandleFunc("/changes/c/review", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	ctx := context.Background()
+
+	var got struct{ X int }
+	if err := NewClient(nil, ts.URL+"/", BasicAuth("user", "pass")).do(ctx, "GET", "/changes/c/comments", nil, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.X != 1 {
+		t.Errorf("got %d, want 1", got.X)
+	}
+	if err := NewClient(nil, ts.URL, NoAuth).do(ctx, "GET", "/changes/c/comments", nil, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.X != 2 {
+		t.Errorf("got %d, want 2", got.X)
+	}
+
+	err := NewClient(nil, ts.URL, NoAuth).do(ctx, "POST", "/changes/c/review", struct{}{}, nil)
+	var rerr UnexpectedResponseError
+	if !errors.As(err, &rerr) || rerr.Code != http.StatusBadRequest {
+		t.Errorf("got unexpected error: %v", err)
+	}
+}
+
+func TestGitCookieFileAuth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gitcookies")
+	cookies := "# comment\n" +
+		".example.com\tTRUE\t/\tTRUE\t2147483647\to\tgit-user=secret\n" +
+		"other.com\tFALSE\t/\tTRUE\t2147483647\to\tother\n"
+	if err := os.WriteFile(path, []byte(cookies), 0600); err != nil {
+		t.Fatal(err)
+	}
+	auth, err := GitCookieFileAuth(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse("https://review.example.com/a/changes/c")
+	req, _ := http.NewRequest("GET", u.String(), nil)
+	auth.setAuth(req, u)
+	c, err := req.Cookie("o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Value != "git-user=secret" {
+		t.Errorf("got cookie %q", c.Value)
+	}
+	if len(req.Cookies()) != 1 {
+		t.Errorf("got unexpected cookies: %v", req.Cookies())
+	}
+
+	if _, err := GitCookieFileAuth(filepath.Join(t.TempDir(), "not-found")); err == nil {
+		t.Error("got no error for missing cookie file")
+	}
+}
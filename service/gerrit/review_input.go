@@ -0,0 +1,52 @@
+package gerrit
+
+// reviewInput is ReviewInput of Gerrit REST API. gerrit.ReviewInput doesn't
+// support robot comments.
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#review-input
+type reviewInput struct {
+	Message       string                         `json:"message,omitempty"`
+	Labels        map[string]int                 `json:"labels,omitempty"`
+	Comments      map[string][]commentInput      `json:"comments,omitempty"`
+	RobotComments map[string][]robotCommentInput `json:"robot_comments,omitempty"`
+	Notify        string                         `json:"notify,omitempty"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#comment-input
+type commentInput struct {
+	Side       string        `json:"side,omitempty"`
+	Line       int           `json:"line,omitempty"`
+	Range      *commentRange `json:"range,omitempty"`
+	Message    string        `json:"message"`
+	Unresolved bool          `json:"unresolved"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#robot-comment-input
+type robotCommentInput struct {
+	commentInput
+	RobotID        string              `json:"robot_id"`
+	RobotRunID     string              `json:"robot_run_id"`
+	URL            string              `json:"url,omitempty"`
+	Properties     map[string]string   `json:"properties,omitempty"`
+	FixSuggestions []fixSuggestionInfo `json:"fix_suggestions,omitempty"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#comment-range
+type commentRange struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#fix-suggestion-info
+type fixSuggestionInfo struct {
+	Description  string               `json:"description"`
+	Replacements []fixReplacementInfo `json:"replacements"`
+}
+
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#fix-replacement-info
+type fixReplacementInfo struct {
+	Path        string       `json:"path"`
+	Range       commentRange `json:"range"`
+	Replacement string       `json:"replacement"`
+}
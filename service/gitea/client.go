@@ -0,0 +1,178 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// reviewsPerPage is the page size of listing reviews. Gitea caps it by
+// MAX_RESPONSE_ITEMS (50 by default).
+const reviewsPerPage = 50
+
+// Client is a client for Gitea (and Forgejo) pull request review API.
+//
+// API document is served by each Gitea instance at /api/swagger.
+type Client struct {
+	cli     *http.Client
+	baseURL string
+	token   string
+}
+
+// Review is a pull request review.
+type Review struct {
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+	State    string `json:"state"`
+	CommitID string `json:"commit_id"`
+}
+
+// ReviewComment is a comment of pull request review.
+type ReviewComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+	Path string `json:"path"`
+	// Position is the line in the new file, and OriginalPosition is the line
+	// in the old file for comments on removed lines.
+	Position         int `json:"position"`
+	OriginalPosition int `json:"original_position"`
+}
+
+// CreateReviewOptions is a request to create a pull request review.
+type CreateReviewOptions struct {
+	Body     string                        `json:"body,omitempty"`
+	Event    string                        `json:"event"`
+	CommitID string                        `json:"commit_id,omitempty"`
+	Comments []*CreateReviewCommentOptions `json:"comments,omitempty"`
+}
+
+// CreateReviewCommentOptions is an inline comment of a review. Either
+// NewLineNum or OldLineNum is set.
+type CreateReviewCommentOptions struct {
+	Path       string `json:"path"`
+	Body       string `json:"body"`
+	NewLineNum int    `json:"new_position,omitempty"`
+	OldLineNum int    `json:"old_position,omitempty"`
+}
+
+// UnexpectedResponseError is returned when Gitea API returns unexpected status code.
+type UnexpectedResponseError struct {
+	Code int
+	Body []byte
+}
+
+func (e UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("gitea api: unexpected response code %d: %s", e.Code, e.Body)
+}
+
+// NewClient returns a new Client. baseURL is the API URL of Gitea, e.g.
+// https://gitea.example.com/api/v1.
+func NewClient(cli *http.Client, baseURL, token string) *Client {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	return &Client{
+		cli:     cli,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+	}
+}
+
+// GetPullRequestDiff returns diff of the pull request in git diff format.
+func (c *Client) GetPullRequestDiff(ctx context.Context, owner, repo string, pr int) ([]byte, error) {
+	d, err := c.do(ctx, http.MethodGet, c.pullRequestURL(owner, repo, pr)+".diff", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request diff: %w", err)
+	}
+	return d, nil
+}
+
+// ListReviews returns all reviews of the pull request.
+func (c *Client) ListReviews(ctx context.Context, owner, repo string, pr int) ([]*Review, error) {
+	var reviews []*Review
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/reviews?page=%d&limit=%d", c.pullRequestURL(owner, repo, pr), page, reviewsPerPage)
+		b, err := c.do(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull request reviews: %w", err)
+		}
+		var rs []*Review
+		if err := json.Unmarshal(b, &rs); err != nil {
+			return nil, fmt.Errorf("failed to parse pull request reviews: %w", err)
+		}
+		reviews = append(reviews, rs...)
+		if len(rs) < reviewsPerPage {
+			return reviews, nil
+		}
+	}
+}
+
+// ListReviewComments returns comments of the review.
+func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, pr int, reviewID int64) ([]*ReviewComment, error) {
+	u := fmt.Sprintf("%s/reviews/%d/comments", c.pullRequestURL(owner, repo, pr), reviewID)
+	b, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull request review comments: %w", err)
+	}
+	var comments []*ReviewComment
+	if err := json.Unmarshal(b, &comments); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request review comments: %w", err)
+	}
+	return comments, nil
+}
+
+// CreateReview creates a review with inline comments on the pull request.
+func (c *Client) CreateReview(ctx context.Context, owner, repo string, pr int, review *CreateReviewOptions) error {
+	if _, err := c.do(ctx, http.MethodPost, c.pullRequestURL(owner, repo, pr)+"/reviews", review); err != nil {
+		return fmt.Errorf("failed to create pull request review: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) pullRequestURL(owner, repo string, pr int) string {
+	return fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, url.PathEscape(owner), url.PathEscape(repo), pr)
+}
+
+// do sends a request with JSON encoded body if it's not nil, and returns the
+// response body.
+func (c *Client) do(ctx context.Context, method, u string, body interface{}) ([]byte, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, UnexpectedResponseError{Code: resp.StatusCode, Body: b}
+	}
+	return b, nil
+}
@@ -0,0 +1,160 @@
+// Package gitea provides reviewdog services for Gitea and Forgejo.
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.CommentService = &PullRequest{}
+var _ reviewdog.DiffService = &PullRequest{}
+
+// PullRequest is a comment and diff service for Gitea PullRequest. It posts
+// all the results in diff as one review with inline comments per Flush.
+//
+// API:
+//  POST /repos/{owner}/{repo}/pulls/{index}/reviews
+type PullRequest struct {
+	cli   *Client
+	owner string
+	repo  string
+	pr    int
+	sha   string
+
+	muComments   sync.Mutex
+	postComments []*reviewdog.Comment
+
+	postedcs commentutil.PostedComments
+
+	// wd is working directory relative to root of repository.
+	wd string
+}
+
+// NewGiteaPullRequest returns a new PullRequest service.
+// PullRequest service needs git command in $PATH.
+func NewGiteaPullRequest(cli *Client, owner, repo string, pr int, sha string) (*PullRequest, error) {
+	workDir, err := serviceutil.GitRelWorkdir()
+	if err != nil {
+		return nil, fmt.Errorf("PullRequest needs 'git' command: %w", err)
+	}
+	return &PullRequest{
+		cli:   cli,
+		owner: owner,
+		repo:  repo,
+		pr:    pr,
+		sha:   sha,
+		wd:    workDir,
+	}, nil
+}
+
+// Post accepts a comment and holds it. Flush method actually posts comments to
+// Gitea as a review.
+func (g *PullRequest) Post(_ context.Context, c *reviewdog.Comment) error {
+	c.Result.Diagnostic.GetLocation().Path = filepath.ToSlash(filepath.Join(g.wd,
+		c.Result.Diagnostic.GetLocation().GetPath()))
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+	g.postComments = append(g.postComments, c)
+	return nil
+}
+
+// Flush posts comments which has not been posted yet.
+func (g *PullRequest) Flush(ctx context.Context) error {
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+
+	if err := g.setPostedComment(ctx); err != nil {
+		return err
+	}
+	return g.postAsReviewComment(ctx)
+}
+
+func (g *PullRequest) postAsReviewComment(ctx context.Context) error {
+	comments := make([]*CreateReviewCommentOptions, 0, len(g.postComments))
+	for _, c := range g.postComments {
+		// Gitea cannot post review comments outside diff. They are reported
+		// to console by the other comment service.
+		if !c.Result.InDiffContext {
+			continue
+		}
+		body := commentutil.MarkdownComment(c)
+		line := giteaCommentLine(c)
+		if g.postedcs.IsPosted(c, line, body) {
+			continue
+		}
+		// Results may be reported twice by the same run.
+		g.postedcs.AddPostedComment(c.Result.Diagnostic.GetLocation().GetPath(), line, body)
+		comments = append(comments, buildReviewComment(c, body))
+	}
+	g.postComments = g.postComments[:0]
+	if len(comments) == 0 {
+		return nil
+	}
+
+	review := &CreateReviewOptions{
+		Event:    "COMMENT",
+		CommitID: g.sha,
+		Comments: comments,
+	}
+	return g.cli.CreateReview(ctx, g.owner, g.repo, g.pr, review)
+}
+
+func buildReviewComment(c *reviewdog.Comment, body string) *CreateReviewCommentOptions {
+	r := &CreateReviewCommentOptions{
+		Path: c.Result.Diagnostic.GetLocation().GetPath(),
+		Body: body,
+	}
+	if c.Result.Diagnostic.GetLocation().GetOld() {
+		r.OldLineNum = giteaCommentLine(c)
+	} else {
+		r.NewLineNum = giteaCommentLine(c)
+	}
+	return r
+}
+
+// giteaCommentLine returns the start line of the result as Gitea doesn't
+// support multi-line review comments.
+func giteaCommentLine(c *reviewdog.Comment) int {
+	return int(c.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
+}
+
+func (g *PullRequest) setPostedComment(ctx context.Context) error {
+	g.postedcs = make(commentutil.PostedComments)
+	reviews, err := g.cli.ListReviews(ctx, g.owner, g.repo, g.pr)
+	if err != nil {
+		return err
+	}
+	for _, r := range reviews {
+		cs, err := g.cli.ListReviewComments(ctx, g.owner, g.repo, g.pr, r.ID)
+		if err != nil {
+			return err
+		}
+		for _, c := range cs {
+			line := c.Position
+			if line == 0 {
+				line = c.OriginalPosition
+			}
+			if c.Path == "" || line == 0 {
+				continue
+			}
+			g.postedcs.AddPostedComment(c.Path, line, c.Body)
+		}
+	}
+	return nil
+}
+
+// Diff returns a diff of PullRequest.
+func (g *PullRequest) Diff(ctx context.Context) ([]byte, error) {
+	return g.cli.GetPullRequestDiff(ctx, g.owner, g.repo, g.pr)
+}
+
+// Strip returns 1 as a strip of git diff.
+func (g *PullRequest) Strip() int {
+	return 1
+}
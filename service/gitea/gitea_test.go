@@ -0,0 +1,144 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+func TestGiteaPullRequest_Post_Flush(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(path string, line int32, old bool, msg string, inDiff bool) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  path,
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+						Old:   old,
+					},
+					Message: msg,
+				},
+				InDiffContext: inDiff,
+			},
+		}
+	}
+	alreadyCommented := newComment("file.go", 1, false, "already commented", true)
+	newComment1 := newComment("file.go", 14, false, "new comment", true)
+	removedLine := newComment("file.go", 3, true, "removed line", true)
+	notInDiff := newComment("file.go", 100, false, "not in diff", false)
+
+	listReviewsCalls := 0
+	var created *CreateReviewOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/o/r/pulls/14/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "token secret"; got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			listReviewsCalls++
+			switch page := r.URL.Query().Get("page"); page {
+			case "1":
+				reviews := make([]*Review, reviewsPerPage)
+				for i := range reviews {
+					reviews[i] = &Review{ID: int64(i + 1)}
+				}
+				json.NewEncoder(w).Encode(reviews)
+			case "2":
+				w.Write([]byte(`[]`))
+			default:
+				t.Errorf("unexpected page: %s", page)
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"id": 100}`))
+		default:
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+	})
+	mux.HandleFunc("/api/v1/repos/o/r/pulls/14/reviews/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/o/r/pulls/14/reviews/1/comments" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		json.NewEncoder(w).Encode([]*ReviewComment{
+			{Path: "file.go", Position: 1, Body: commentutil.MarkdownComment(alreadyCommented)},
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli := NewClient(nil, ts.URL+"/api/v1", "secret")
+	g, err := NewGiteaPullRequest(cli, "o", "r", 14, "sha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*reviewdog.Comment{alreadyCommented, newComment1, removedLine, notInDiff} {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if listReviewsCalls != 2 {
+		t.Errorf("list reviews API called %d times, want 2", listReviewsCalls)
+	}
+	want := &CreateReviewOptions{
+		Event:    "COMMENT",
+		CommitID: "sha",
+		Comments: []*CreateReviewCommentOptions{
+			{Path: "file.go", Body: commentutil.MarkdownComment(newComment1), NewLineNum: 14},
+			{Path: "file.go", Body: commentutil.MarkdownComment(removedLine), OldLineNum: 3},
+		},
+	}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Errorf("created review diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGiteaPullRequest_Diff(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	want := "diff --git a/file.go b/file.go\n"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/o/r/pulls/14.diff", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		w.Write([]byte(want))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	g, err := NewGiteaPullRequest(NewClient(nil, ts.URL+"/api/v1", ""), "o", "r", 14, "sha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
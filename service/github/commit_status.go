@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/go-github/v39/github"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.BulkCommentService = &CommitStatus{}
+
+const (
+	commitStatusSuccess = "success"
+	commitStatusFailure = "failure"
+	commitStatusPending = "pending"
+
+	// GitHub rejects commit status descriptions longer than 140 characters.
+	maxCommitStatusDescription = 140
+)
+
+// CommitStatus is a comment service which reports results as commit statuses,
+// one status per tool named "reviewdog/<tool>". It's useful when Checks API
+// is not available (e.g. GitHub Enterprise without GitHub Apps).
+//
+// API:
+//	https://docs.github.com/en/rest/reference/repos#create-a-commit-status
+//	POST /repos/:owner/:repo/statuses/:sha
+type CommitStatus struct {
+	cli       *github.Client
+	owner     string
+	repo      string
+	sha       string
+	targetURL string
+
+	mu      sync.Mutex
+	runners *commentutil.Runners
+	counts  *serviceutil.ToolSeverityCounts
+	// posted holds the last posted state and description per tool name.
+	posted map[string]string
+}
+
+// NewGitHubCommitStatus returns a new CommitStatus service. It sets pending
+// statuses for given runners, so that a tool without any findings gets a
+// success status at the end as well. Statuses stay pending until all the
+// runners are flushed, so a runner which fails never gets a status. level is
+// used as the severity of diagnostics which don't have severity. targetURL is
+// optional.
+func NewGitHubCommitStatus(ctx context.Context, cli *github.Client, owner, repo, sha string, runners []string, level, targetURL string) (*CommitStatus, error) {
+	s := &CommitStatus{
+		cli:       cli,
+		owner:     owner,
+		repo:      repo,
+		sha:       sha,
+		targetURL: targetURL,
+		runners:   commentutil.NewRunners(runners),
+		posted:    make(map[string]string, len(runners)),
+	}
+	s.counts = serviceutil.NewToolSeverityCounts(s.runners.Names(), level)
+	for _, runner := range s.runners.Names() {
+		if err := s.createStatus(ctx, runner, commitStatusPending, "reviewdog is checking your code"); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Post accepts a comment and holds it. Flush method actually posts statuses.
+func (s *CommitStatus) Post(_ context.Context, c *reviewdog.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts.Add(c.ToolName, c.Result.Diagnostic)
+	return nil
+}
+
+// Flush posts a commit status per tool once all the runners are flushed. It
+// skips tools whose status is not changed since the last Flush.
+func (s *CommitStatus) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.runners.Flush() {
+		return nil
+	}
+	for _, tool := range s.counts.Tools() {
+		counts := s.counts.Get(tool)
+		state := commitStatusSuccess
+		if counts.AtOrAbove(rdf.Severity_ERROR) {
+			state = commitStatusFailure
+		}
+		if err := s.createStatus(ctx, tool, state, counts.Description()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CommitStatus) createStatus(ctx context.Context, tool, state, desc string) error {
+	desc = serviceutil.TruncateDescription(desc, maxCommitStatusDescription)
+	if s.posted[tool] == state+desc {
+		return nil
+	}
+	status := &github.RepoStatus{
+		State:       github.String(state),
+		Description: github.String(desc),
+		Context:     github.String(serviceutil.CommitStatusName(tool)),
+	}
+	if s.targetURL != "" {
+		status.TargetURL = github.String(s.targetURL)
+	}
+	if _, _, err := s.cli.Repositories.CreateStatus(ctx, s.owner, s.repo, s.sha, status); err != nil {
+		return fmt.Errorf("failed to create commit status for %s: %w", tool, err)
+	}
+	s.posted[tool] = state + desc
+	return nil
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v39/github"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCommitStatus_Post_Flush(t *testing.T) {
+	var got []*github.RepoStatus
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/statuses/sha", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		var status github.RepoStatus
+		if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+			t.Error(err)
+		}
+		got = append(got, &status)
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			t.Fatal(err)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli := github.NewClient(nil)
+	cli.BaseURL, _ = url.Parse(ts.URL + "/")
+
+	ctx := context.Background()
+	s, err := NewGitHubCommitStatus(ctx, cli, "o", "r", "sha", []string{"clean-linter", "error-linter", "warning-linter"}, "error", "https://example.com/report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments := []*reviewdog.Comment{
+		{
+			ToolName: "error-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_ERROR}},
+		},
+		{
+			ToolName: "error-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{}}, // Use -level.
+		},
+		{
+			ToolName: "error-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_INFO}},
+		},
+		{
+			ToolName: "warning-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_WARNING}},
+		},
+	}
+	for _, c := range comments {
+		if err := s.Post(ctx, c); err != nil {
+			t.Error(err)
+		}
+	}
+	// Flush is called per runner. Statuses stay pending until all the runners
+	// are flushed.
+	for i := 0; i < 2; i++ {
+		if err := s.Flush(ctx); err != nil {
+			t.Error(err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("posted %d statuses before all the runners are flushed, want 3 pending statuses", len(got))
+		}
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Error(err)
+	}
+	// Flush again should not post the same statuses.
+	if err := s.Flush(ctx); err != nil {
+		t.Error(err)
+	}
+
+	newStatus := func(context, state, desc string) *github.RepoStatus {
+		return &github.RepoStatus{
+			Context:     github.String(context),
+			State:       github.String(state),
+			Description: github.String(desc),
+			TargetURL:   github.String("https://example.com/report"),
+		}
+	}
+	want := []*github.RepoStatus{
+		newStatus("reviewdog/clean-linter", "pending", "reviewdog is checking your code"),
+		newStatus("reviewdog/error-linter", "pending", "reviewdog is checking your code"),
+		newStatus("reviewdog/warning-linter", "pending", "reviewdog is checking your code"),
+		newStatus("reviewdog/clean-linter", "success", "No findings"),
+		newStatus("reviewdog/error-linter", "failure", "Found 3 finding(s): 2 error, 1 info"),
+		newStatus("reviewdog/warning-linter", "success", "Found 1 finding(s): 1 warning"),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("posted statuses diff: (-got +want)\n%s", diff)
+	}
+}
@@ -90,12 +90,13 @@ func (g *PullRequest) Flush(ctx context.Context) error {
 
 func (g *PullRequest) postAsReviewComment(ctx context.Context) error {
 	comments := make([]*github.DraftReviewComment, 0, len(g.postComments))
-	remaining := make([]*reviewdog.Comment, 0)
 	for _, c := range g.postComments {
 		if !c.Result.InDiffContext {
 			// GitHub Review API cannot report results outside diff. If it's running
 			// in GitHub Actions, fallback to GitHub Actions log as report .
-			if cienv.IsInGitHubAction() {
+			// Results on the old file cannot be annotated as the file no longer
+			// exists in the checked out revision.
+			if cienv.IsInGitHubAction() && !c.Result.Diagnostic.GetLocation().GetOld() {
 				githubutils.ReportAsGitHubActionsLog(c.ToolName, "warning", c.Result.Diagnostic)
 			}
 			continue
@@ -104,53 +105,66 @@ func (g *PullRequest) postAsReviewComment(ctx context.Context) error {
 		if g.postedcs.IsPosted(c, githubCommentLine(c), body) {
 			continue
 		}
-		// Only posts maxCommentsPerRequest comments per 1 request to avoid spammy
-		// review comments. An example GitHub error if we don't limit the # of
-		// review comments.
-		//
-		// > 403 You have triggered an abuse detection mechanism and have been
-		// > temporarily blocked from content creation. Please retry your request
-		// > again later.
-		// https://developer.github.com/v3/#abuse-rate-limits
-		if len(comments) >= maxCommentsPerRequest {
-			remaining = append(remaining, c)
-			continue
-		}
 		comments = append(comments, buildDraftReviewComment(c, body))
 	}
 
-	if len(comments) == 0 {
-		return nil
-	}
-
-	review := &github.PullRequestReviewRequest{
-		CommitID: &g.sha,
-		Event:    github.String("COMMENT"),
-		Comments: comments,
-		Body:     github.String(g.remainingCommentsSummary(remaining)),
+	// Only posts maxCommentsPerRequest comments per 1 request to avoid spammy
+	// review comments and post the rest as subsequent reviews. An example
+	// GitHub error if we don't limit the # of review comments.
+	//
+	// > 403 You have triggered an abuse detection mechanism and have been
+	// > temporarily blocked from content creation. Please retry your request
+	// > again later.
+	// https://developer.github.com/v3/#abuse-rate-limits
+	//
+	// Reviews are posted sequentially as concurrent requests are likely to
+	// trigger the secondary rate limit.
+	for len(comments) > 0 {
+		n := len(comments)
+		if n > maxCommentsPerRequest {
+			n = maxCommentsPerRequest
+		}
+		review := &github.PullRequestReviewRequest{
+			CommitID: &g.sha,
+			Event:    github.String("COMMENT"),
+			Comments: comments[:n],
+		}
+		if _, _, err := g.cli.PullRequests.CreateReview(ctx, g.owner, g.repo, g.pr, review); err != nil {
+			return err
+		}
+		comments = comments[n:]
 	}
-	_, _, err := g.cli.PullRequests.CreateReview(ctx, g.owner, g.repo, g.pr, review)
-	return err
+	return nil
 }
 
 // Document: https://docs.github.com/en/rest/reference/pulls#create-a-review-comment-for-a-pull-request
 func buildDraftReviewComment(c *reviewdog.Comment, body string) *github.DraftReviewComment {
 	loc := c.Result.Diagnostic.GetLocation()
 	startLine, endLine := githubCommentLineRange(c)
+	side := githubCommentSide(c)
 	r := &github.DraftReviewComment{
 		Path: github.String(loc.GetPath()),
-		Side: github.String("RIGHT"),
+		Side: github.String(side),
 		Body: github.String(body),
 		Line: github.Int(endLine),
 	}
 	// GitHub API: Start line must precede the end line.
 	if startLine < endLine {
-		r.StartSide = github.String("RIGHT")
+		r.StartSide = github.String(side)
 		r.StartLine = github.Int(startLine)
 	}
 	return r
 }
 
+// githubCommentSide returns "LEFT" for results on the old file (e.g. deleted
+// lines), otherwise "RIGHT".
+func githubCommentSide(c *reviewdog.Comment) string {
+	if c.Result.Diagnostic.GetLocation().GetOld() {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
+
 // line represents end line if it's a multiline comment in GitHub, otherwise
 // it's start line.
 // Document: https://docs.github.com/en/rest/reference/pulls#create-a-review-comment-for-a-pull-request
@@ -181,30 +195,6 @@ func githubCommentLineRange(c *reviewdog.Comment) (start int, end int) {
 	return int(startLine), int(endLine)
 }
 
-func (g *PullRequest) remainingCommentsSummary(remaining []*reviewdog.Comment) string {
-	if len(remaining) == 0 {
-		return ""
-	}
-	perTool := make(map[string][]*reviewdog.Comment)
-	for _, c := range remaining {
-		perTool[c.ToolName] = append(perTool[c.ToolName], c)
-	}
-	var sb strings.Builder
-	sb.WriteString("Remaining comments which cannot be posted as a review comment to avoid GitHub Rate Limit\n")
-	sb.WriteString("\n")
-	for tool, comments := range perTool {
-		sb.WriteString("<details>\n")
-		sb.WriteString(fmt.Sprintf("<summary>%s</summary>\n", tool))
-		sb.WriteString("\n")
-		for _, c := range comments {
-			sb.WriteString(githubutils.LinkedMarkdownDiagnostic(g.owner, g.repo, g.sha, c.Result.Diagnostic))
-			sb.WriteString("\n")
-		}
-		sb.WriteString("</details>\n")
-	}
-	return sb.String()
-}
-
 func (g *PullRequest) setPostedComment(ctx context.Context) error {
 	g.postedcs = make(commentutil.PostedComments)
 	cs, err := g.comment(ctx)
@@ -294,6 +284,9 @@ func buildSuggestions(c *reviewdog.Comment) string {
 }
 
 func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
+	if c.Result.Diagnostic.GetLocation().GetOld() {
+		return "", errors.New("GitHub cannot apply suggestions to the old file (LEFT side)")
+	}
 	start := s.GetRange().GetStart()
 	startLine := int(start.GetLine())
 	end := s.GetRange().GetEnd()
@@ -326,22 +319,10 @@ func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, err
 }
 
 func buildNonLineBasedSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
-	sourceLines := c.Result.SourceLines
-	if len(sourceLines) == 0 {
-		return "", errors.New("source lines are not available")
-	}
-	start := s.GetRange().GetStart()
-	end := s.GetRange().GetEnd()
-	startLineContent, err := getSourceLine(sourceLines, int(start.GetLine()))
+	txt, err := commentutil.NonLineBasedSuggestionText(c.Result.SourceLines, s)
 	if err != nil {
 		return "", err
 	}
-	endLineContent, err := getSourceLine(sourceLines, int(end.GetLine()))
-	if err != nil {
-		return "", err
-	}
-
-	txt := startLineContent[:max(start.GetColumn()-1, 0)] + s.GetText() + endLineContent[max(end.GetColumn()-1, 0):]
 	backticks := commentutil.GetCodeFenceLength(txt)
 
 	var sb strings.Builder
@@ -353,18 +334,3 @@ func buildNonLineBasedSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (strin
 	commentutil.WriteCodeFence(&sb, backticks)
 	return sb.String(), nil
 }
-
-func getSourceLine(sourceLines map[int]string, line int) (string, error) {
-	lineContent, ok := sourceLines[line]
-	if !ok {
-		return "", fmt.Errorf("source line (L=%d) is not available for this suggestion", line)
-	}
-	return lineContent, nil
-}
-
-func max(x, y int32) int32 {
-	if x < y {
-		return y
-	}
-	return x
-}
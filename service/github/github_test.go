@@ -476,6 +476,23 @@ func TestGitHubPullRequest_Post_Flush_review_api(t *testing.T) {
 					"``````",
 				}, "\n") + "\n"),
 			},
+			{
+				Path: github.String("reviewdog.go"),
+				Side: github.String("LEFT"),
+				Line: github.Int(15),
+				Body: github.String(commentutil.BodyPrefix + "comment on deleted line"),
+			},
+			{
+				Path:      github.String("reviewdog.go"),
+				Side:      github.String("LEFT"),
+				StartSide: github.String("LEFT"),
+				StartLine: github.Int(15),
+				Line:      github.Int(16),
+				Body: github.String(commentutil.BodyPrefix + strings.Join([]string{
+					"suggestion on deleted lines",
+					invalidSuggestionPre + "GitHub cannot apply suggestions to the old file (LEFT side)" + invalidSuggestionPost,
+				}, "\n") + "\n"),
+			},
 		}
 		if diff := pretty.Compare(want, req.Comments); diff != "" {
 			t.Errorf("req.Comments diff: (-got +want)\n%s", diff)
@@ -1077,6 +1094,56 @@ func TestGitHubPullRequest_Post_Flush_review_api(t *testing.T) {
 				InDiffContext: true,
 			},
 		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "reviewdog.go",
+						Range: &rdf.Range{
+							Start: &rdf.Position{
+								Line: 15,
+							},
+						},
+						Old: true,
+					},
+					Message: "comment on deleted line",
+				},
+				InDiffContext: true,
+			},
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "reviewdog.go",
+						Range: &rdf.Range{
+							Start: &rdf.Position{
+								Line: 15,
+							},
+							End: &rdf.Position{
+								Line: 16,
+							},
+						},
+						Old: true,
+					},
+					Suggestions: []*rdf.Suggestion{
+						{
+							Range: &rdf.Range{
+								Start: &rdf.Position{
+									Line: 15,
+								},
+								End: &rdf.Position{
+									Line: 16,
+								},
+							},
+							Text: "line1\nline2",
+						},
+					},
+					Message: "suggestion on deleted lines",
+				},
+				InDiffContext: true,
+			},
+		},
 	}
 	for _, c := range comments {
 		if err := g.Post(context.Background(), c); err != nil {
@@ -1102,6 +1169,7 @@ func TestGitHubPullRequest_Post_toomany(t *testing.T) {
 
 	listCommentsAPICalled := 0
 	postCommentsAPICalled := 0
+	var postedLines []int
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/repos/o/r/pulls/14/comments", func(w http.ResponseWriter, r *http.Request) {
@@ -1116,8 +1184,11 @@ func TestGitHubPullRequest_Post_toomany(t *testing.T) {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			t.Error(err)
 		}
-		if req.GetBody() == "" {
-			t.Errorf("PullRequestReviewRequest.Body is empty but want some summary text")
+		if len(req.Comments) > maxCommentsPerRequest {
+			t.Errorf("PullRequestReviewRequest has %d comments, want at most %d", len(req.Comments), maxCommentsPerRequest)
+		}
+		for _, c := range req.Comments {
+			postedLines = append(postedLines, c.GetLine())
 		}
 	})
 	ts := httptest.NewServer(mux)
@@ -1158,9 +1229,18 @@ func TestGitHubPullRequest_Post_toomany(t *testing.T) {
 	if want := 1; listCommentsAPICalled != want {
 		t.Errorf("GitHub List PullRequest comments API called %v times, want %d times", listCommentsAPICalled, want)
 	}
-	if want := 1; postCommentsAPICalled != want {
+	if want := 4; postCommentsAPICalled != want {
 		t.Errorf("GitHub post PullRequest comments API called %v times, want %d times", postCommentsAPICalled, want)
 	}
+	// All comments should be posted in order across the reviews.
+	if len(postedLines) != len(comments) {
+		t.Fatalf("posted %d comments, want %d", len(postedLines), len(comments))
+	}
+	for i, l := range postedLines {
+		if l != i {
+			t.Errorf("posted comments[%d] at line %d, want %d", i, l, i)
+		}
+	}
 }
 
 func TestGitHubPullRequest_workdir(t *testing.T) {
@@ -0,0 +1,184 @@
+package githubutils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultBaseDelay  = time.Second
+	// defaultMaxWait is the longest wait before a retry. reviewdog gives up
+	// retrying rather than blocking CI for a long time if GitHub asks to wait
+	// longer (e.g. until the primary rate limit is reset).
+	defaultMaxWait = 2 * time.Minute
+)
+
+// reviewCreationPath matches the path of "Create a review for a pull request"
+// API. It's not idempotent, so it's retried only when it's rejected by rate
+// limits before GitHub creates the review.
+var reviewCreationPath = regexp.MustCompile(`/repos/[^/]+/[^/]+/pulls/\d+/reviews$`)
+
+// RetryTransport is a http.RoundTripper for GitHub API which retries requests
+// rejected by rate limits (including secondary rate limits a.k.a. abuse
+// detection) and server errors. It honours Retry-After and X-RateLimit-Reset
+// headers and otherwise waits with jittered exponential backoff.
+//
+// Idempotent requests are retried on rate limits, server errors and network
+// errors. Review creation requests are retried on rate limits only as GitHub
+// may have created the review before returning server errors.
+// https://docs.github.com/en/rest/guides/best-practices-for-integrators#dealing-with-secondary-rate-limits
+type RetryTransport struct {
+	base http.RoundTripper
+
+	maxRetries int
+	baseDelay  time.Duration
+	maxWait    time.Duration
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport returns new RetryTransport which sends requests with base.
+// http.DefaultTransport is used if base is nil.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{
+		base:       base,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxWait:    defaultMaxWait,
+		now:        time.Now,
+		sleep:      sleepCtx,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isRetryableRequest(req) {
+		return t.base.RoundTrip(req)
+	}
+	idempotent := isIdempotent(req.Method)
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		resp, err := t.base.RoundTrip(r)
+		if attempt >= t.maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+		wait, retry := t.retryAfter(attempt, resp, err, idempotent)
+		if !retry || wait > t.maxWait {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		log.Printf("reviewdog: retrying %s %s in %v (attempt %d)", req.Method, req.URL.Path, wait, attempt+1)
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter returns how long to wait before the next attempt and whether the
+// request should be retried. Non-idempotent requests are retried only when
+// they are rejected by rate limits.
+func (t *RetryTransport) retryAfter(attempt int, resp *http.Response, err error, idempotent bool) (time.Duration, bool) {
+	if err != nil {
+		return t.backoff(attempt), idempotent
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && isRateLimited(resp):
+		if s := resp.Header.Get("Retry-After"); s != "" {
+			if sec, err := strconv.Atoi(s); err == nil {
+				return time.Duration(sec) * time.Second, true
+			}
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				if d := time.Unix(reset, 0).Sub(t.now()); d > 0 {
+					return d, true
+				}
+			}
+		}
+		return t.backoff(attempt), true
+	case resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return t.backoff(attempt), idempotent
+	}
+	return 0, false
+}
+
+// backoff returns exponential backoff duration with jitter, which is in
+// [d/2, d) where d = baseDelay * 2^attempt.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	d := t.baseDelay << uint(attempt)
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isRateLimited returns true if the given 403 response is caused by rate
+// limits rather than permissions.
+func isRateLimited(resp *http.Response) bool {
+	if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+	// Secondary rate limit responses may not have the headers above.
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(b, []byte("secondary rate limit")) || bytes.Contains(b, []byte("abuse detection"))
+}
+
+func isRetryableRequest(req *http.Request) bool {
+	if req.Body != nil && req.GetBody == nil {
+		// Cannot rewind the body.
+		return false
+	}
+	return isIdempotent(req.Method) ||
+		req.Method == http.MethodPost && reviewCreationPath.MatchString(req.URL.Path)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
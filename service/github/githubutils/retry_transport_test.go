@@ -0,0 +1,201 @@
+package githubutils
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		responses []func(w http.ResponseWriter)
+		wantCalls int
+		wantCode  int
+		wantWaits []time.Duration
+	}{
+		{
+			name:   "retry after",
+			method: http.MethodGet,
+			path:   "/repos/o/r/pulls/14/comments",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "3")
+					w.WriteHeader(http.StatusForbidden)
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			wantCalls: 2,
+			wantCode:  http.StatusOK,
+			wantWaits: []time.Duration{3 * time.Second},
+		},
+		{
+			name:   "rate limit reset",
+			method: http.MethodGet,
+			path:   "/repos/o/r/pulls/14/files",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(10*time.Second).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			wantCalls: 2,
+			wantCode:  http.StatusOK,
+			wantWaits: []time.Duration{10 * time.Second},
+		},
+		{
+			name:   "rate limit reset too late",
+			method: http.MethodGet,
+			path:   "/repos/o/r/pulls/14/files",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusForbidden,
+		},
+		{
+			name:   "review creation with secondary rate limit",
+			method: http.MethodPost,
+			path:   "/repos/o/r/pulls/14/reviews",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			},
+			// The review may have been created before 502, so it's not retried.
+			wantCalls: 2,
+			wantCode:  http.StatusBadGateway,
+		},
+		{
+			name:   "review creation with rate limit",
+			method: http.MethodPost,
+			path:   "/repos/o/r/pulls/14/reviews",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusTooManyRequests) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			wantCalls: 2,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:   "permission error",
+			method: http.MethodGet,
+			path:   "/repos/o/r/pulls/14/comments",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+				},
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusForbidden,
+		},
+		{
+			name:   "non idempotent request",
+			method: http.MethodPost,
+			path:   "/repos/o/r/issues/14/comments",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusServiceUnavailable,
+		},
+		{
+			name:   "give up",
+			method: http.MethodGet,
+			path:   "/repos/o/r/pulls/14/comments",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			},
+			wantCalls: 3,
+			wantCode:  http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					b, _ := ioutil.ReadAll(r.Body)
+					if string(b) != `{"body":"b"}` {
+						t.Errorf("got body %q on call %d", b, calls+1)
+					}
+				}
+				if calls < len(tt.responses) {
+					tt.responses[calls](w)
+				} else {
+					t.Errorf("unexpected call %d", calls+1)
+				}
+				calls++
+			}))
+			defer ts.Close()
+
+			tr := NewRetryTransport(nil)
+			tr.maxRetries = 2
+			tr.now = func() time.Time { return now }
+			var waits []time.Duration
+			tr.sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+			if tt.method == http.MethodPost {
+				req, err = http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(`{"body":"b"}`))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&http.Client{Transport: tr}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantWaits != nil {
+				if len(waits) != len(tt.wantWaits) {
+					t.Fatalf("got waits %v, want %v", waits, tt.wantWaits)
+				}
+				for i := range waits {
+					if waits[i] != tt.wantWaits[i] {
+						t.Errorf("got waits %v, want %v", waits, tt.wantWaits)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransport_backoff(t *testing.T) {
+	tr := NewRetryTransport(nil)
+	for attempt := 0; attempt < 5; attempt++ {
+		d := defaultBaseDelay << uint(attempt)
+		for i := 0; i < 10; i++ {
+			if got := tr.backoff(attempt); got < d/2 || got > d {
+				t.Errorf("backoff(%d) = %v, want in [%v, %v]", attempt, got, d/2, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package githubutils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+// MaxStepSummarySize is the maximum size of job summary per step.
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#step-isolation-and-limits
+const MaxStepSummarySize = 1024 * 1024
+
+// maxTruncationNoticeSize is the size reserved for the notice of omitted
+// results per section.
+const maxTruncationNoticeSize = 256
+
+var _ reviewdog.BulkCommentService = &StepSummaryWriter{}
+
+// StepSummaryWriter reports results as Markdown to the job summary of GitHub
+// Actions ($GITHUB_STEP_SUMMARY). Unlike GitHubActionLogWriter, it's not
+// limited by the number of annotations per step, so it's useful to show all
+// results for Pull Requests from forked repository.
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#adding-a-job-summary
+type StepSummaryWriter struct {
+	path  string
+	owner string
+	repo  string
+	sha   string
+
+	mu sync.Mutex
+	// comments per tool name.
+	comments map[string][]*reviewdog.Comment
+}
+
+// NewStepSummaryWriter returns new StepSummaryWriter which appends results to
+// the given job summary file. owner, repo, and sha are used to build links to
+// the location of results.
+func NewStepSummaryWriter(path, owner, repo, sha string) *StepSummaryWriter {
+	return &StepSummaryWriter{
+		path:     path,
+		owner:    owner,
+		repo:     repo,
+		sha:      sha,
+		comments: make(map[string][]*reviewdog.Comment),
+	}
+}
+
+// Post accepts a comment and holds it. Flush method actually writes results.
+func (s *StepSummaryWriter) Post(_ context.Context, c *reviewdog.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.comments[c.ToolName] = append(s.comments[c.ToolName], c)
+	return nil
+}
+
+// Flush appends a collapsible section per tool to the job summary. Results
+// which don't fit in the size limit of job summary are omitted.
+func (s *StepSummaryWriter) Flush(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.comments) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open job summary: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	remaining := MaxStepSummarySize - int(fi.Size())
+
+	tools := make([]string, 0, len(s.comments))
+	for tool := range s.comments {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	var sb strings.Builder
+	for _, tool := range tools {
+		section := s.buildSection(tool, s.comments[tool], remaining-sb.Len())
+		if section == "" {
+			log.Printf("reviewdog: results of %q are not written to job summary due to the size limit", tool)
+		}
+		sb.WriteString(section)
+		delete(s.comments, tool)
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+	return nil
+}
+
+// buildSection builds a collapsible section of the given tool within size.
+// It returns an empty string if even the header doesn't fit.
+func (s *StepSummaryWriter) buildSection(tool string, comments []*reviewdog.Comment, size int) string {
+	header := fmt.Sprintf("<details>\n<summary><b>[%s]</b> reviewdog found %d result(s)</summary>\n\n", tool, len(comments))
+	footer := "\n</details>\n\n"
+	budget := size - len(header) - len(footer) - maxTruncationNoticeSize
+	if budget < 0 {
+		return ""
+	}
+	var body strings.Builder
+	omitted := 0
+	for i, c := range comments {
+		item := s.buildItem(c)
+		if body.Len()+len(item) > budget {
+			omitted = len(comments) - i
+			break
+		}
+		body.WriteString(item)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&body, "\n:warning: %d result(s) are omitted due to the size limit of job summary. See the job log for all results.\n", omitted)
+	}
+	return header + body.String() + footer
+}
+
+// buildItem builds a Markdown list item of the given comment. Suggestions are
+// rendered as diff blocks as job summary cannot apply them.
+func (s *StepSummaryWriter) buildItem(c *reviewdog.Comment) string {
+	d := c.Result.Diagnostic
+	var sb strings.Builder
+	sb.WriteString("- ")
+	if icon := severityIcon(d.GetSeverity()); icon != "" {
+		sb.WriteString(icon + " ")
+	}
+	sb.WriteString(indent(LinkedMarkdownDiagnostic(s.owner, s.repo, s.sha, d)))
+	sb.WriteString("\n")
+	for _, suggestion := range d.GetSuggestions() {
+		txt, ok := buildDiffSuggestion(c, suggestion)
+		if !ok {
+			continue
+		}
+		sb.WriteString("\n  ")
+		sb.WriteString(indent(txt))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// buildDiffSuggestion builds a diff block of the given suggestion. The removed
+// lines are available only when the source lines are available.
+func buildDiffSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, bool) {
+	sd := serviceutil.BuildSuggestionDiff(s, func(lnum int) (string, bool) {
+		line, ok := c.Result.SourceLines[lnum]
+		return line, ok
+	})
+	if len(sd.Old) == 0 && (s.GetRange().GetStart().GetColumn() > 0 || s.GetRange().GetEnd().GetColumn() > 0) {
+		// Column based suggestion needs the source lines to build new lines.
+		return "", false
+	}
+
+	var diff strings.Builder
+	for _, l := range sd.Old {
+		diff.WriteString("-" + l + "\n")
+	}
+	for _, l := range sd.New {
+		diff.WriteString("+" + l + "\n")
+	}
+	backticks := commentutil.GetCodeFenceLength(diff.String())
+
+	var sb strings.Builder
+	commentutil.WriteCodeFence(&sb, backticks)
+	sb.WriteString("diff\n")
+	sb.WriteString(diff.String())
+	commentutil.WriteCodeFence(&sb, backticks)
+	return sb.String(), true
+}
+
+func severityIcon(s rdf.Severity) string {
+	switch s {
+	case rdf.Severity_ERROR:
+		return "🚫"
+	case rdf.Severity_WARNING:
+		return "⚠️"
+	case rdf.Severity_INFO:
+		return "📝"
+	}
+	return ""
+}
+
+// indent indents the continuation lines to keep them in a list item.
+func indent(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n  ")
+}
@@ -0,0 +1,170 @@
+package githubutils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestStepSummaryWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(path, []byte("# Existing summary\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s := NewStepSummaryWriter(path, "o", "r", "sha")
+	comments := []*reviewdog.Comment{
+		{
+			ToolName: "tool-b",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "b.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+					},
+					Message: "multi\nline",
+				},
+			},
+		},
+		{
+			ToolName: "tool-a",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "a.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 2, Column: 5}},
+					},
+					Message:  "line based",
+					Severity: rdf.Severity_ERROR,
+					Suggestions: []*rdf.Suggestion{
+						{
+							Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+							Text:  "new line",
+						},
+					},
+				},
+				SourceLines: map[int]string{2: "old line"},
+			},
+		},
+		{
+			ToolName: "tool-a",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "a.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 3, Column: 5}},
+					},
+					Message:  "column based",
+					Severity: rdf.Severity_WARNING,
+					Suggestions: []*rdf.Suggestion{
+						{
+							Range: &rdf.Range{
+								Start: &rdf.Position{Line: 3, Column: 5},
+								End:   &rdf.Position{Line: 3, Column: 8},
+							},
+							Text: "new",
+						},
+						{
+							// Insertion at the start position.
+							Range: &rdf.Range{Start: &rdf.Position{Line: 3, Column: 5}},
+							Text:  "very ",
+						},
+						{
+							// Column based suggestion without source lines is skipped.
+							Range: &rdf.Range{
+								Start: &rdf.Position{Line: 4, Column: 1},
+								End:   &rdf.Position{Line: 4, Column: 2},
+							},
+							Text: "x",
+						},
+					},
+				},
+				SourceLines: map[int]string{3: "foo old bar"},
+			},
+		},
+	}
+	for _, c := range comments {
+		if err := s.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Flush again should not write the same results.
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Existing summary\n\n" +
+		"<details>\n<summary><b>[tool-a]</b> reviewdog found 2 result(s)</summary>\n\n" +
+		"- 🚫 [a.go|2 col 5|](http://github.com/o/r/blob/sha/a.go#L2) line based\n" +
+		"\n  ```diff\n  -old line\n  +new line\n  ```\n" +
+		"- ⚠️ [a.go|3 col 5|](http://github.com/o/r/blob/sha/a.go#L3) column based\n" +
+		"\n  ```diff\n  -foo old bar\n  +foo new bar\n  ```\n" +
+		"\n  ```diff\n  -foo old bar\n  +foo very old bar\n  ```\n" +
+		"\n</details>\n\n" +
+		"<details>\n<summary><b>[tool-b]</b> reviewdog found 1 result(s)</summary>\n\n" +
+		"- [b.go|1|](http://github.com/o/r/blob/sha/b.go#L1) multi\n  line\n" +
+		"\n</details>\n\n"
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("job summary diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestStepSummaryWriter_truncate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	// Leave space for a few results.
+	existing := strings.Repeat("x", MaxStepSummarySize-1000)
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s := NewStepSummaryWriter(path, "o", "r", "sha")
+	for i := 0; i < 100; i++ {
+		c := &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "a.go"},
+					Message:  strings.Repeat("m", 50),
+				},
+			},
+		}
+		if err := s.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) > MaxStepSummarySize {
+		t.Errorf("job summary exceeds the size limit: %d", len(got))
+	}
+	summary := string(got[len(existing):])
+	if !strings.Contains(summary, "reviewdog found 100 result(s)") {
+		t.Errorf("section header not found:\n%s", summary)
+	}
+	if n := strings.Count(summary, "\n- "); n == 0 || n >= 100 {
+		t.Errorf("got %d results, want some of them", n)
+	}
+	if !strings.Contains(summary, "result(s) are omitted due to the size limit of job summary") {
+		t.Errorf("truncation notice not found:\n%s", summary)
+	}
+}
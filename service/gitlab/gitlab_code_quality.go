@@ -0,0 +1,182 @@
+package gitlab
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.BulkCommentService = &CodeQualityReport{}
+
+// CodeQualityReport is a comment service which writes results as GitLab Code
+// Quality report (a subset of Code Climate spec). GitLab shows the report
+// artifact in MergeRequest widget and diff without API tokens.
+//
+// Document:
+//	https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool
+type CodeQualityReport struct {
+	path  string
+	level string
+
+	mu     sync.Mutex
+	issues []*codeQualityIssue
+	posted map[codeQualityIssue]bool
+
+	// wd is working directory relative to root of repository.
+	wd string
+}
+
+type codeQualityIssue struct {
+	Type        string              `json:"type"`
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	EngineName  string              `json:"engine_name,omitempty"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+
+	// Column is the start column of the issue, which orders issues on the
+	// same line. It's not a part of the report.
+	Column int `json:"-"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// NewGitLabCodeQualityReport returns a new CodeQualityReport service which
+// writes the report to the given path. level is used as the severity of
+// results which don't have severity.
+// CodeQualityReport service needs git command in $PATH.
+func NewGitLabCodeQualityReport(path, level string) (*CodeQualityReport, error) {
+	workDir, err := serviceutil.GitRelWorkdir()
+	if err != nil {
+		return nil, fmt.Errorf("CodeQualityReport needs 'git' command: %w", err)
+	}
+	return &CodeQualityReport{path: path, level: level, wd: workDir, posted: make(map[codeQualityIssue]bool)}, nil
+}
+
+// Post accepts a comment and holds it as an issue of the report. Identical
+// issues are reported only once. Results on the old file (e.g. deleted lines)
+// are skipped, as Code Quality reports only have locations in the new file.
+func (r *CodeQualityReport) Post(_ context.Context, c *reviewdog.Comment) error {
+	d := c.Result.Diagnostic
+	loc := d.GetLocation()
+	if loc.GetOld() {
+		return nil
+	}
+	start := int(loc.GetRange().GetStart().GetLine())
+	end := int(loc.GetRange().GetEnd().GetLine())
+	if end < start {
+		end = start
+	}
+	checkName := d.GetCode().GetValue()
+	if checkName == "" {
+		checkName = c.ToolName
+	}
+	path := loc.GetPath()
+	if path != "" {
+		path = filepath.ToSlash(filepath.Join(r.wd, path))
+	}
+	issue := &codeQualityIssue{
+		Type:        "issue",
+		Description: d.GetMessage(),
+		CheckName:   checkName,
+		EngineName:  c.ToolName,
+		Severity:    r.severity(d),
+		Location: codeQualityLocation{
+			Path:  path,
+			Lines: codeQualityLines{Begin: start, End: end},
+		},
+		Column: int(loc.GetRange().GetStart().GetColumn()),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.posted[*issue] {
+		return nil
+	}
+	r.posted[*issue] = true
+	r.issues = append(r.issues, issue)
+	return nil
+}
+
+// Flush writes the report with all the issues posted so far. It's called per
+// tool in project mode, so it overwrites the report every time.
+func (r *CodeQualityReport) Flush(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	issues := r.issues
+	if issues == nil {
+		// GitLab expects an array even if there are no issues.
+		issues = []*codeQualityIssue{}
+	}
+	setCodeQualityFingerprints(issues)
+	b, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write GitLab Code Quality report: %w", err)
+	}
+	return nil
+}
+
+// severity maps severity of the diagnostic to Code Quality severity (info,
+// minor, major, critical, or blocker).
+func (r *CodeQualityReport) severity(d *rdf.Diagnostic) string {
+	switch serviceutil.Severity(d, r.level) {
+	case rdf.Severity_INFO:
+		return "info"
+	case rdf.Severity_WARNING:
+		return "minor"
+	}
+	return "major"
+}
+
+// setCodeQualityFingerprints sets fingerprints of the issues, which GitLab
+// uses to compare issues between the source and target branch. Fingerprints
+// don't include line numbers so that issues keep them when lines above are
+// changed. Issues with the same check and description in a file are told
+// apart by their order in the file.
+func setCodeQualityFingerprints(issues []*codeQualityIssue) {
+	groups := make(map[string][]*codeQualityIssue)
+	var keys []string
+	for _, issue := range issues {
+		key := fmt.Sprintf("%s\x00%s\x00%s\x00%s",
+			issue.EngineName, issue.CheckName, issue.Location.Path, issue.Description)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], issue)
+	}
+	for _, key := range keys {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			a, b := group[i], group[j]
+			if a.Location.Lines.Begin != b.Location.Lines.Begin {
+				return a.Location.Lines.Begin < b.Location.Lines.Begin
+			}
+			return a.Column < b.Column
+		})
+		for i, issue := range group {
+			h := md5.New()
+			fmt.Fprintf(h, "%s\x00%d", key, i)
+			issue.Fingerprint = fmt.Sprintf("%x", h.Sum(nil))
+		}
+	}
+}
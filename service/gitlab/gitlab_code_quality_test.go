@@ -0,0 +1,225 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCodeQualityReport(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	path := filepath.Join(t.TempDir(), "gl-code-quality-report.json")
+	r, err := NewGitLabCodeQualityReport(path, "warning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Write an empty report if there are no issues.
+	if err := r.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "[]\n" {
+		t.Errorf("got %q, want empty array", b)
+	}
+
+	comments := []*reviewdog.Comment{
+		{
+			ToolName: "golint",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "a.go",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 1, Column: 2},
+							End:   &rdf.Position{Line: 3},
+						},
+					},
+					Message:  "error message",
+					Severity: rdf.Severity_ERROR,
+					Code:     &rdf.Code{Value: "rule-1"},
+				},
+			},
+		},
+		{
+			ToolName: "govet",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "b.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Message: "message without severity",
+				},
+			},
+		},
+	}
+	// Flush per tool like project mode.
+	for _, c := range comments {
+		if err := r.Post(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*codeQualityIssue
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []*codeQualityIssue{
+		{
+			Type:        "issue",
+			Description: "error message",
+			CheckName:   "rule-1",
+			EngineName:  "golint",
+			Severity:    "major",
+			Location: codeQualityLocation{
+				Path:  "a.go",
+				Lines: codeQualityLines{Begin: 1, End: 3},
+			},
+		},
+		{
+			Type:        "issue",
+			Description: "message without severity",
+			CheckName:   "govet",
+			EngineName:  "govet",
+			Severity:    "minor",
+			Location: codeQualityLocation{
+				Path:  "b.go",
+				Lines: codeQualityLines{Begin: 14, End: 14},
+			},
+		},
+	}
+	for i, issue := range got {
+		if issue.Fingerprint == "" {
+			t.Errorf("issue[%d] doesn't have fingerprint", i)
+		}
+		issue.Fingerprint = ""
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("report diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestCodeQualityReport_fingerprint(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	comment := func(line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "golint",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "a.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+			},
+		}
+	}
+	report := func(comments ...*reviewdog.Comment) []*codeQualityIssue {
+		path := filepath.Join(t.TempDir(), "gl-code-quality-report.json")
+		r, err := NewGitLabCodeQualityReport(path, "warning")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+		for _, c := range comments {
+			if err := r.Post(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var issues []*codeQualityIssue
+		if err := json.Unmarshal(b, &issues); err != nil {
+			t.Fatal(err)
+		}
+		return issues
+	}
+
+	before := report(comment(1, "msg"), comment(5, "msg"), comment(5, "msg"), comment(7, "other"))
+	if len(before) != 3 {
+		t.Fatalf("got %d issues, want 3 as identical issues are reported once", len(before))
+	}
+	if before[0].Fingerprint == before[1].Fingerprint {
+		t.Errorf("repeated issues have the same fingerprint %q", before[0].Fingerprint)
+	}
+
+	// Lines are added above the issues.
+	after := report(comment(17, "other"), comment(15, "msg"), comment(11, "msg"))
+	got := map[string]bool{}
+	for _, issue := range after {
+		got[issue.Fingerprint] = true
+	}
+	for _, issue := range before {
+		if !got[issue.Fingerprint] {
+			t.Errorf("fingerprint of %q at line %d changed", issue.Description, issue.Location.Lines.Begin)
+		}
+	}
+}
+
+func TestCodeQualityReport_old(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	path := filepath.Join(t.TempDir(), "gl-code-quality-report.json")
+	r, err := NewGitLabCodeQualityReport(path, "warning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	c := &reviewdog.Comment{
+		ToolName: "golint",
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "a.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 3}},
+					Old:   true,
+				},
+				Message: "deleted line",
+			},
+		},
+	}
+	if err := r.Post(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(b)); got != "[]" {
+		t.Errorf("report = %s, want no issues for results on the old file", got)
+	}
+}
@@ -0,0 +1,123 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.BulkCommentService = &CommitStatus{}
+
+// GitLab rejects commit status descriptions longer than 255 characters.
+const maxCommitStatusDescription = 255
+
+// CommitStatus is a comment service which reports results as commit statuses,
+// one status per tool named "reviewdog/<tool>". GitLab shows them as external
+// jobs of the pipeline in MergeRequest widgets.
+//
+// API:
+//	https://docs.gitlab.com/ee/api/commits.html#post-the-build-status-to-a-commit
+//	POST /projects/:id/statuses/:sha
+type CommitStatus struct {
+	cli        *gitlab.Client
+	projects   string
+	sha        string
+	ref        string
+	pipelineID int
+	targetURL  string
+
+	mu      sync.Mutex
+	runners *commentutil.Runners
+	counts  *serviceutil.ToolSeverityCounts
+	// posted holds the last posted state and description per tool name.
+	posted map[string]string
+}
+
+// NewGitLabCommitStatus returns a new CommitStatus service. It sets running
+// statuses for given runners, so that a tool without any findings gets a
+// success status at the end as well. Statuses stay running until all the
+// runners are flushed, so a runner which fails never gets a final status.
+// level is used as the severity of diagnostics which don't have severity. ref,
+// pipelineID, and targetURL are optional.
+func NewGitLabCommitStatus(ctx context.Context, cli *gitlab.Client, owner, repo, sha, ref string, pipelineID int, runners []string, level, targetURL string) (*CommitStatus, error) {
+	s := &CommitStatus{
+		cli:        cli,
+		projects:   owner + "/" + repo,
+		sha:        sha,
+		ref:        ref,
+		pipelineID: pipelineID,
+		targetURL:  targetURL,
+		runners:    commentutil.NewRunners(runners),
+		posted:     make(map[string]string, len(runners)),
+	}
+	s.counts = serviceutil.NewToolSeverityCounts(s.runners.Names(), level)
+	for _, runner := range s.runners.Names() {
+		if err := s.setStatus(ctx, runner, gitlab.Running, "reviewdog is checking your code"); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Post accepts a comment and holds it. Flush method actually posts statuses.
+func (s *CommitStatus) Post(_ context.Context, c *reviewdog.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts.Add(c.ToolName, c.Result.Diagnostic)
+	return nil
+}
+
+// Flush posts a commit status per tool once all the runners are flushed. It
+// skips tools whose status is not changed since the last Flush.
+func (s *CommitStatus) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.runners.Flush() {
+		return nil
+	}
+	for _, tool := range s.counts.Tools() {
+		counts := s.counts.Get(tool)
+		state := gitlab.Success
+		if counts.AtOrAbove(rdf.Severity_ERROR) {
+			state = gitlab.Failed
+		}
+		if err := s.setStatus(ctx, tool, state, counts.Description()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CommitStatus) setStatus(ctx context.Context, tool string, state gitlab.BuildStateValue, desc string) error {
+	desc = serviceutil.TruncateDescription(desc, maxCommitStatusDescription)
+	if s.posted[tool] == string(state)+desc {
+		return nil
+	}
+	opt := &gitlab.SetCommitStatusOptions{
+		State:       state,
+		Name:        gitlab.String(serviceutil.CommitStatusName(tool)),
+		Description: gitlab.String(desc),
+	}
+	if s.ref != "" {
+		opt.Ref = gitlab.String(s.ref)
+	}
+	if s.pipelineID != 0 {
+		opt.PipelineID = gitlab.Int(s.pipelineID)
+	}
+	if s.targetURL != "" {
+		opt.TargetURL = gitlab.String(s.targetURL)
+	}
+	if _, _, err := s.cli.Commits.SetCommitStatus(s.projects, s.sha, opt, gitlab.WithContext(ctx)); err != nil {
+		return fmt.Errorf("failed to set commit status for %s: %w", tool, err)
+	}
+	s.posted[tool] = string(state) + desc
+	return nil
+}
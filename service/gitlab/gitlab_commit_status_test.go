@@ -0,0 +1,109 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCommitStatus_Post_Flush(t *testing.T) {
+	var got []*gitlab.SetCommitStatusOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o/r/statuses/sha", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		var opt gitlab.SetCommitStatusOptions
+		if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+			t.Error(err)
+		}
+		got = append(got, &opt)
+		if err := json.NewEncoder(w).Encode(gitlab.CommitStatus{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	s, err := NewGitLabCommitStatus(ctx, cli, "o", "r", "sha", "feature", 1, []string{"clean-linter", "error-linter", "warning-linter"}, "error", "https://example.com/job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments := []*reviewdog.Comment{
+		{
+			ToolName: "error-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_ERROR}},
+		},
+		{
+			ToolName: "error-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{}}, // Use -level.
+		},
+		{
+			ToolName: "error-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_INFO}},
+		},
+		{
+			ToolName: "warning-linter",
+			Result:   &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_WARNING}},
+		},
+	}
+	for _, c := range comments {
+		if err := s.Post(ctx, c); err != nil {
+			t.Error(err)
+		}
+	}
+	// Flush is called per runner. Statuses stay running until all the runners
+	// are flushed.
+	for i := 0; i < 2; i++ {
+		if err := s.Flush(ctx); err != nil {
+			t.Error(err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("posted %d statuses before all the runners are flushed, want 3 running statuses", len(got))
+		}
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Error(err)
+	}
+	// Flush again should not post the same statuses.
+	if err := s.Flush(ctx); err != nil {
+		t.Error(err)
+	}
+
+	newStatus := func(name string, state gitlab.BuildStateValue, desc string) *gitlab.SetCommitStatusOptions {
+		return &gitlab.SetCommitStatusOptions{
+			State:       state,
+			Ref:         gitlab.String("feature"),
+			Name:        gitlab.String(name),
+			Description: gitlab.String(desc),
+			PipelineID:  gitlab.Int(1),
+			TargetURL:   gitlab.String("https://example.com/job"),
+		}
+	}
+	want := []*gitlab.SetCommitStatusOptions{
+		newStatus("reviewdog/clean-linter", gitlab.Running, "reviewdog is checking your code"),
+		newStatus("reviewdog/error-linter", gitlab.Running, "reviewdog is checking your code"),
+		newStatus("reviewdog/warning-linter", gitlab.Running, "reviewdog is checking your code"),
+		newStatus("reviewdog/clean-linter", gitlab.Success, "No findings"),
+		newStatus("reviewdog/error-linter", gitlab.Failed, "Found 3 finding(s): 2 error, 1 info"),
+		newStatus("reviewdog/warning-linter", gitlab.Success, "Found 1 finding(s): 1 warning"),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("posted statuses diff: (-got +want)\n%s", diff)
+	}
+}
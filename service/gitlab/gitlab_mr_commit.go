@@ -3,8 +3,10 @@ package gitlab
 import (
 	"context"
 	"fmt"
+	"log"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -34,13 +36,23 @@ type MergeRequestCommitCommenter struct {
 
 	postedcs commentutil.PostedComments
 
+	// headOnly posts all comments to the head commit instead of the commits
+	// which last touched the lines.
+	headOnly bool
+
+	// blames caches commit IDs by line number of each file.
+	blames map[string]map[int]string
+	blame  func(path string) (map[int]string, error)
+
 	// wd is working directory relative to root of repository.
 	wd string
 }
 
 // NewGitLabMergeRequestCommitCommenter returns a new MergeRequestCommitCommenter service.
+// It posts each comment to the MergeRequest commit which last touched the
+// line, or to the head commit if headOnly is true.
 // MergeRequestCommitCommenter service needs git command in $PATH.
-func NewGitLabMergeRequestCommitCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string) (*MergeRequestCommitCommenter, error) {
+func NewGitLabMergeRequestCommitCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string, headOnly bool) (*MergeRequestCommitCommenter, error) {
 	workDir, err := serviceutil.GitRelWorkdir()
 	if err != nil {
 		return nil, fmt.Errorf("MergeRequestCommitCommenter needs 'git' command: %w", err)
@@ -50,6 +62,9 @@ func NewGitLabMergeRequestCommitCommenter(cli *gitlab.Client, owner, repo string
 		pr:       pr,
 		sha:      sha,
 		projects: owner + "/" + repo,
+		headOnly: headOnly,
+		blames:   make(map[string]map[int]string),
+		blame:    gitBlame,
 		wd:       workDir,
 	}, nil
 }
@@ -70,14 +85,37 @@ func (g *MergeRequestCommitCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
 
-	if err := g.setPostedComment(ctx); err != nil {
+	commits, err := g.listAllMergeRequestCommits(ctx)
+	if err != nil {
 		return err
 	}
+	g.setPostedComment(ctx, commits)
+
+	return g.postCommentsForEach(ctx, commits)
+}
 
-	return g.postCommentsForEach(ctx)
+func (g *MergeRequestCommitCommenter) listAllMergeRequestCommits(ctx context.Context) ([]*gitlab.Commit, error) {
+	var commits []*gitlab.Commit
+	opt := &gitlab.GetMergeRequestCommitsOptions{PerPage: 100}
+	for {
+		cs, resp, err := g.cli.MergeRequests.GetMergeRequestCommits(
+			g.projects, g.pr, opt, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, cs...)
+		if resp.NextPage == 0 {
+			return commits, nil
+		}
+		opt.Page = resp.NextPage
+	}
 }
 
-func (g *MergeRequestCommitCommenter) postCommentsForEach(ctx context.Context) error {
+func (g *MergeRequestCommitCommenter) postCommentsForEach(ctx context.Context, commits []*gitlab.Commit) error {
+	mrCommits := make(map[string]bool, len(commits))
+	for _, c := range commits {
+		mrCommits[c.ID] = true
+	}
 	var eg errgroup.Group
 	for _, c := range g.postComments {
 		c := c
@@ -87,41 +125,101 @@ func (g *MergeRequestCommitCommenter) postCommentsForEach(ctx context.Context) e
 		if !c.Result.InDiffFile || lnum == 0 || g.postedcs.IsPosted(c, lnum, body) {
 			continue
 		}
+		commitID := g.sha
+		lineType := "new"
+		if loc.GetOld() {
+			// Deleted lines cannot be blamed in the current revision.
+			lineType = "old"
+		} else if !g.headOnly {
+			commitID = g.lastCommitID(loc.GetPath(), lnum, mrCommits)
+		}
 		eg.Go(func() error {
-			commitID, err := g.getLastCommitsID(loc.GetPath(), lnum)
-			if err != nil {
-				commitID = g.sha
-			}
 			prcomment := &gitlab.PostCommitCommentOptions{
 				Note:     gitlab.String(body),
 				Path:     gitlab.String(loc.GetPath()),
 				Line:     gitlab.Int(lnum),
-				LineType: gitlab.String("new"),
+				LineType: gitlab.String(lineType),
 			}
-			_, _, err = g.cli.Commits.PostCommitComment(g.projects, commitID, prcomment, gitlab.WithContext(ctx))
+			_, _, err := g.cli.Commits.PostCommitComment(g.projects, commitID, prcomment, gitlab.WithContext(ctx))
 			return err
 		})
 	}
 	return eg.Wait()
 }
 
-func (g *MergeRequestCommitCommenter) getLastCommitsID(path string, line int) (string, error) {
-	lineFormat := fmt.Sprintf("%d,%d", line, line)
-	s, err := exec.Command("git", "blame", "-l", "-L", lineFormat, path).Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get commitID: %w", err)
+// lastCommitID returns the MergeRequest commit which last touched the given
+// line. It returns the head commit if the commit is not a part of the
+// MergeRequest (e.g. unchanged lines in diff context or uncommitted changes)
+// or the file cannot be blamed.
+func (g *MergeRequestCommitCommenter) lastCommitID(path string, line int, mrCommits map[string]bool) string {
+	lines, ok := g.blames[path]
+	if !ok {
+		var err error
+		if lines, err = g.blame(path); err != nil {
+			log.Printf("reviewdog: failed to get commitID of %s, posting to the head commit: %v", path, err)
+			lines = map[int]string{}
+		}
+		g.blames[path] = lines
 	}
-	commitID := strings.Split(string(s), " ")[0]
-	return commitID, nil
+	if id := lines[line]; mrCommits[id] {
+		return id
+	}
+	return g.sha
 }
 
-func (g *MergeRequestCommitCommenter) setPostedComment(ctx context.Context) error {
-	g.postedcs = make(commentutil.PostedComments)
-	cs, err := g.comment(ctx)
+// gitBlame returns commit IDs by line number of the given path relative to
+// root of repository.
+func gitBlame(path string) (map[int]string, error) {
+	root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to run 'git rev-parse --show-toplevel': %w", err)
+	}
+	cmd := exec.Command("git", "blame", "--porcelain", "--", path)
+	cmd.Dir = strings.TrimSpace(string(root))
+	b, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run 'git blame': %w", err)
 	}
-	for _, c := range cs {
+	return parseBlamePorcelain(string(b)), nil
+}
+
+// parseBlamePorcelain parses output of 'git blame --porcelain'. Each line of
+// the file has a header line "<sha> <orig-line> <final-line> [<num-lines>]"
+// followed by optional commit information and the content prefixed by TAB.
+//
+// https://git-scm.com/docs/git-blame#_the_porcelain_format
+func parseBlamePorcelain(out string) map[int]string {
+	lines := make(map[int]string)
+	for _, l := range strings.Split(out, "\n") {
+		if strings.HasPrefix(l, "\t") {
+			continue
+		}
+		fs := strings.Fields(l)
+		if len(fs) < 3 || !isCommitID(fs[0]) {
+			continue
+		}
+		if n, err := strconv.Atoi(fs[2]); err == nil {
+			lines[n] = fs[0]
+		}
+	}
+	return lines
+}
+
+func isCommitID(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *MergeRequestCommitCommenter) setPostedComment(ctx context.Context, commits []*gitlab.Commit) {
+	g.postedcs = make(commentutil.PostedComments)
+	for _, c := range g.comment(ctx, commits) {
 		if c.Line == 0 || c.Path == "" || c.Note == "" {
 			// skip resolved comments. Or comments which do not have "path" nor
 			// "body".
@@ -129,15 +227,9 @@ func (g *MergeRequestCommitCommenter) setPostedComment(ctx context.Context) erro
 		}
 		g.postedcs.AddPostedComment(c.Path, c.Line, c.Note)
 	}
-	return nil
 }
 
-func (g *MergeRequestCommitCommenter) comment(ctx context.Context) ([]*gitlab.CommitComment, error) {
-	commits, _, err := g.cli.MergeRequests.GetMergeRequestCommits(
-		g.projects, g.pr, nil, gitlab.WithContext(ctx))
-	if err != nil {
-		return nil, err
-	}
+func (g *MergeRequestCommitCommenter) comment(ctx context.Context, commits []*gitlab.Commit) []*gitlab.CommitComment {
 	comments := make([]*gitlab.CommitComment, 0)
 	for _, c := range commits {
 		tmpComments, _, err := g.cli.Commits.GetCommitComments(
@@ -147,5 +239,5 @@ func (g *MergeRequestCommitCommenter) comment(ctx context.Context) ([]*gitlab.Co
 		}
 		comments = append(comments, tmpComments...)
 	}
-	return comments, nil
+	return comments
 }
@@ -3,12 +3,15 @@ package gitlab
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
-	"github.com/kylelemons/godebug/pretty"
+	"github.com/google/go-cmp/cmp"
 	"github.com/xanzy/go-gitlab"
 
 	"github.com/reviewdog/reviewdog"
@@ -22,115 +25,187 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 	defer os.Chdir(cwd)
 	os.Chdir("../..")
 
-	apiCalled := 0
-	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/commits", func(w http.ResponseWriter, r *http.Request) {
-		apiCalled++
-		if r.Method != http.MethodGet {
-			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
-		}
-		cs := []*gitlab.Commit{
-			{
-				ID:      "0123456789abcdef",
-				ShortID: "012345678",
-			},
-		}
-		if err := json.NewEncoder(w).Encode(cs); err != nil {
+	const (
+		mrCommit    = "0123456789abcdef0123456789abcdef01234567"
+		mrCommit2   = "abcdef0123456789abcdef0123456789abcdef01"
+		otherCommit = "fedcba9876543210fedcba9876543210fedcba98"
+	)
+
+	for _, tt := range []struct {
+		headOnly bool
+		// want is commit IDs by line of posted comments.
+		want map[int]string
+	}{
+		{
+			headOnly: false,
+			want:     map[int]string{14: mrCommit, 15: "sha", 16: mrCommit2, 20: "sha", 21: "sha"},
+		},
+		{
+			headOnly: true,
+			want:     map[int]string{14: "sha", 15: "sha", 16: "sha", 20: "sha", 21: "sha"},
+		},
+	} {
+		apiCalled := 0
+		got := make(map[int]string)
+		var mu sync.Mutex
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/commits", func(w http.ResponseWriter, r *http.Request) {
+			apiCalled++
+			if r.Method != http.MethodGet {
+				t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+			}
+			cs := []*gitlab.Commit{
+				{
+					ID:      mrCommit,
+					ShortID: "012345678",
+				},
+			}
+			switch r.URL.Query().Get("page") {
+			case "2":
+				cs = []*gitlab.Commit{{ID: mrCommit2}}
+			default:
+				w.Header().Add("X-Next-Page", "2")
+			}
+			if err := json.NewEncoder(w).Encode(cs); err != nil {
+				t.Fatal(err)
+			}
+		})
+		mux.HandleFunc("/api/v4/projects/o/r/repository/commits/", func(w http.ResponseWriter, r *http.Request) {
+			commitID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v4/projects/o/r/repository/commits/"), "/comments")
+			switch r.Method {
+			case http.MethodGet:
+				if commitID == mrCommit2 {
+					w.Write([]byte(`[]`))
+					return
+				}
+				if commitID != mrCommit {
+					t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+				}
+				cs := []*gitlab.CommitComment{
+					{
+						Path: "file.go",
+						Line: 1,
+						Note: commentutil.BodyPrefix + "already commented",
+					},
+				}
+				if err := json.NewEncoder(w).Encode(cs); err != nil {
+					t.Fatal(err)
+				}
+			case http.MethodPost:
+				var req gitlab.CommitComment
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+				mu.Lock()
+				got[req.Line] = commitID
+				mu.Unlock()
+				if err := json.NewEncoder(w).Encode(req); err != nil {
+					t.Fatal(err)
+				}
+			default:
+				t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+			}
+		})
+		ts := httptest.NewServer(mux)
+
+		cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+		if err != nil {
 			t.Fatal(err)
 		}
-	})
-	mux.HandleFunc("/api/v4/projects/o/r/repository/commits/0123456789abcdef/comments", func(w http.ResponseWriter, r *http.Request) {
-		apiCalled++
-		if r.Method != http.MethodGet {
-			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+
+		g, err := NewGitLabMergeRequestCommitCommenter(cli, "o", "r", 14, "sha", tt.headOnly)
+		if err != nil {
+			t.Fatal(err)
 		}
-		cs := []*gitlab.CommitComment{
-			{
-				Path: "notExistFile.go",
-				Line: 1,
-				Note: commentutil.BodyPrefix + "already commented",
-			},
+		blameCalled := 0
+		g.blame = func(path string) (map[int]string, error) {
+			blameCalled++
+			switch path {
+			case "file.go":
+				return map[int]string{1: otherCommit, 14: mrCommit, 15: otherCommit, 16: mrCommit2}, nil
+			case "untracked.go":
+				return nil, errors.New("no such path in HEAD")
+			}
+			t.Errorf("blame(%q) is called", path)
+			return nil, nil
 		}
-		if err := json.NewEncoder(w).Encode(cs); err != nil {
-			t.Fatal(err)
+		untracked1 := buildTestCommitComment("untracked", 20)
+		untracked1.Result.Diagnostic.Location.Path = "untracked.go"
+		untracked2 := buildTestCommitComment("untracked 2", 21)
+		untracked2.Result.Diagnostic.Location.Path = "untracked.go"
+		comments := []*reviewdog.Comment{
+			buildTestCommitComment("already commented", 1),
+			buildTestCommitComment("new comment", 14),
+			buildTestCommitComment("new comment 2", 15),
+			buildTestCommitComment("new comment 3", 16),
+			untracked1,
+			untracked2,
 		}
-	})
-	mux.HandleFunc("/api/v4/projects/o/r/repository/commits/sha/comments", func(w http.ResponseWriter, r *http.Request) {
-		apiCalled++
-		if r.Method != http.MethodPost {
-			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		for _, c := range comments {
+			if err := g.Post(context.Background(), c); err != nil {
+				t.Error(err)
+			}
 		}
-		var req gitlab.CommitComment
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := g.Flush(context.Background()); err != nil {
 			t.Error(err)
 		}
-		want := gitlab.CommitComment{
-			Path:     "notExistFile.go",
-			Line:     14,
-			Note:     commentutil.BodyPrefix + "new comment",
-			LineType: "new",
+		if want := 2; apiCalled != want {
+			t.Errorf("GitLab MergeRequest commits API is called %d times, want %d times", apiCalled, want)
 		}
-		if diff := pretty.Compare(want, req); diff != "" {
-			t.Errorf("req.Comments diff: (-got +want)\n%s", diff)
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("headOnly=%v: posted comments diff: (-got +want)\n%s", tt.headOnly, diff)
 		}
-		if err := json.NewEncoder(w).Encode(req); err != nil {
-			t.Fatal(err)
+		// Files are blamed once even if blame fails.
+		wantBlameCalled := 2
+		if tt.headOnly {
+			wantBlameCalled = 0
 		}
-	})
-	ts := httptest.NewServer(mux)
-	defer ts.Close()
-
-	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
-	if err != nil {
-		t.Fatal(err)
+		if blameCalled != wantBlameCalled {
+			t.Errorf("headOnly=%v: blame is called %d times, want %d times", tt.headOnly, blameCalled, wantBlameCalled)
+		}
+		ts.Close()
 	}
+}
 
-	g, err := NewGitLabMergeRequestCommitCommenter(cli, "o", "r", 14, "sha")
-	if err != nil {
-		t.Fatal(err)
-	}
-	// Path is set to non existing file path for mock test not to use last commit id of the line.
-	// If setting exists file path, sha is changed by last commit id.
-	comments := []*reviewdog.Comment{
-		{
-			Result: &filter.FilteredDiagnostic{
-				Diagnostic: &rdf.Diagnostic{
-					Location: &rdf.Location{
-						Path: "notExistFile.go",
-						Range: &rdf.Range{Start: &rdf.Position{
-							Line: 1,
-						}},
-					},
-					Message: "already commented",
-				},
-				InDiffFile: true,
-			},
-		},
-		{
-			Result: &filter.FilteredDiagnostic{
-				Diagnostic: &rdf.Diagnostic{
-					Location: &rdf.Location{
-						Path: "notExistFile.go",
-						Range: &rdf.Range{Start: &rdf.Position{
-							Line: 14,
-						}},
-					},
-					Message: "new comment",
+func buildTestCommitComment(msg string, line int32) *reviewdog.Comment {
+	return &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: line}},
 				},
-				InDiffFile: true,
+				Message: msg,
 			},
+			InDiffFile: true,
 		},
 	}
-	for _, c := range comments {
-		if err := g.Post(context.Background(), c); err != nil {
-			t.Error(err)
-		}
-	}
-	if err := g.Flush(context.Background()); err != nil {
-		t.Error(err)
+}
+
+func TestParseBlamePorcelain(t *testing.T) {
+	out := strings.Join([]string{
+		"0123456789abcdef0123456789abcdef01234567 1 1 2",
+		"author a",
+		"author-mail <a@example.com>",
+		"summary 0123456789abcdef0123456789abcdef01234567 1 1",
+		"previous fedcba9876543210fedcba9876543210fedcba98 file.go",
+		"filename file.go",
+		"\tline1",
+		"0123456789abcdef0123456789abcdef01234567 2 2",
+		"\tfedcba9876543210fedcba9876543210fedcba98 3 3",
+		"0000000000000000000000000000000000000000 3 3 1",
+		"author Not Committed Yet",
+		"filename file.go",
+		"\tline3",
+		"",
+	}, "\n")
+	want := map[int]string{
+		1: "0123456789abcdef0123456789abcdef01234567",
+		2: "0123456789abcdef0123456789abcdef01234567",
+		3: "0000000000000000000000000000000000000000",
 	}
-	if want := 3; apiCalled != want {
-		t.Errorf("GitLab API is called %d times, want %d times", apiCalled, want)
+	if diff := cmp.Diff(parseBlamePorcelain(out), want); diff != "" {
+		t.Errorf("parseBlamePorcelain() diff: (-got +want)\n%s", diff)
 	}
 }
 
@@ -139,7 +214,7 @@ func TestGitLabPullRequest_workdir(t *testing.T) {
 	defer os.Chdir(cwd)
 	os.Chdir("../..")
 
-	g, err := NewGitLabMergeRequestCommitCommenter(nil, "", "", 0, "")
+	g, err := NewGitLabMergeRequestCommitCommenter(nil, "", "", 0, "", false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -158,7 +233,7 @@ func TestGitLabPullRequest_workdir(t *testing.T) {
 	if err := os.Chdir(subDir); err != nil {
 		t.Fatal(err)
 	}
-	g, _ = NewGitLabMergeRequestCommitCommenter(nil, "", "", 0, "")
+	g, _ = NewGitLabMergeRequestCommitCommenter(nil, "", "", 0, "", false)
 	if g.wd != subDir {
 		t.Fatalf("gitRelWorkdir() = %q, want %q", g.wd, subDir)
 	}
@@ -2,6 +2,8 @@ package gitlab
 
 import (
 	"context"
+	"crypto/sha1"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strconv"
@@ -36,13 +38,23 @@ type MergeRequestDiscussionCommenter struct {
 	muComments   sync.Mutex
 	postComments []*reviewdog.Comment
 
+	// runners whose stale discussions are resolved after all of them are
+	// flushed.
+	runners *commentutil.Runners
+	// reopen resolved discussions if their results are reported again.
+	reopen bool
+
 	// wd is working directory relative to root of repository.
 	wd string
 }
 
 // NewGitLabMergeRequestDiscussionCommenter returns a new MergeRequestDiscussionCommenter service.
 // MergeRequestDiscussionCommenter service needs git command in $PATH.
-func NewGitLabMergeRequestDiscussionCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string) (*MergeRequestDiscussionCommenter, error) {
+//
+// Discussions posted by reviewdog for the given runners are resolved when
+// their results are no longer reported. If reopen is true, resolved ones are
+// unresolved when their results are reported again.
+func NewGitLabMergeRequestDiscussionCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string, runners []string, reopen bool) (*MergeRequestDiscussionCommenter, error) {
 	workDir, err := serviceutil.GitRelWorkdir()
 	if err != nil {
 		return nil, fmt.Errorf("MergeRequestDiscussionCommenter needs 'git' command: %w", err)
@@ -52,6 +64,8 @@ func NewGitLabMergeRequestDiscussionCommenter(cli *gitlab.Client, owner, repo st
 		pr:       pr,
 		sha:      sha,
 		projects: owner + "/" + repo,
+		runners:  commentutil.NewRunners(runners),
+		reopen:   reopen,
 		wd:       workDir,
 	}, nil
 }
@@ -67,77 +81,131 @@ func (g *MergeRequestDiscussionCommenter) Post(_ context.Context, c *reviewdog.C
 	return nil
 }
 
-// Flush posts comments which has not been posted yet.
+// Flush posts comments which has not been posted yet. Once all the runners
+// are flushed, it resolves stale discussions as well.
 func (g *MergeRequestDiscussionCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
-	postedcs, err := g.createPostedComments()
+	discussions, err := listAllMergeRequestDiscussion(g.cli, g.projects, g.pr, &gitlab.ListMergeRequestDiscussionsOptions{PerPage: 100})
 	if err != nil {
-		return fmt.Errorf("failed to create posted comments: %w", err)
+		return fmt.Errorf("failed to list all merge request discussions: %w", err)
+	}
+	postedcs, postedNotes := createPostedComments(discussions)
+	if err := g.postCommentsForEach(ctx, postedcs, postedNotes); err != nil {
+		return err
 	}
-	return g.postCommentsForEach(ctx, postedcs)
+	if !g.runners.Flush() || g.runners.Len() == 0 {
+		return nil
+	}
+	return g.resolveDiscussions(ctx, discussions)
 }
 
-func (g *MergeRequestDiscussionCommenter) createPostedComments() (commentutil.PostedComments, error) {
+// createPostedComments returns posted discussions on diff and bodies of posted
+// general notes which don't have position.
+func createPostedComments(discussions []*gitlab.Discussion) (commentutil.PostedComments, map[string]bool) {
 	postedcs := make(commentutil.PostedComments)
-	discussions, err := listAllMergeRequestDiscussion(g.cli, g.projects, g.pr, &gitlab.ListMergeRequestDiscussionsOptions{PerPage: 100})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list all merge request discussions: %w", err)
-	}
+	postedNotes := make(map[string]bool)
 	for _, d := range discussions {
 		for _, note := range d.Notes {
 			pos := note.Position
-			if pos == nil || pos.NewPath == "" || pos.NewLine == 0 || note.Body == "" {
+			if note.Body == "" {
 				continue
 			}
-			postedcs.AddPostedComment(pos.NewPath, pos.NewLine, note.Body)
+			if pos == nil {
+				postedNotes[note.Body] = true
+				continue
+			}
+			if pos.NewPath != "" && pos.NewLine != 0 {
+				postedcs.AddPostedComment(pos.NewPath, pos.NewLine, note.Body)
+			} else if pos.OldPath != "" && pos.OldLine != 0 {
+				// Comment on a deleted line.
+				postedcs.AddPostedComment(pos.OldPath, pos.OldLine, note.Body)
+			}
 		}
 	}
-	return postedcs, nil
+	return postedcs, postedNotes
 }
 
-func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Context, postedcs commentutil.PostedComments) error {
+func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Context, postedcs commentutil.PostedComments, postedNotes map[string]bool) error {
 	mr, _, err := g.cli.MergeRequests.GetMergeRequest(g.projects, g.pr, nil, gitlab.WithContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to get merge request: %w", err)
 	}
-	targetBranch, _, err := g.cli.Branches.GetBranch(mr.TargetProjectID, mr.TargetBranch, nil)
-	if err != nil {
-		return err
+	// Use diff_refs of the merge request so that positions match the diff
+	// shown in the merge request even if the target branch has moved.
+	// https://docs.gitlab.com/ee/api/discussions.html#create-a-new-thread-in-the-merge-request-diff
+	headSHA := mr.DiffRefs.HeadSha
+	if headSHA == "" {
+		headSHA = g.sha
+	}
+	startSHA, baseSHA := mr.DiffRefs.StartSha, mr.DiffRefs.BaseSha
+	if startSHA == "" || baseSHA == "" {
+		// diff_refs can be empty while GitLab is still preparing the diff.
+		// Fall back to the head of the target branch.
+		targetBranch, _, err := g.cli.Branches.GetBranch(mr.TargetProjectID, mr.TargetBranch, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("merge request has no diff_refs and failed to get target branch %q: %w", mr.TargetBranch, err)
+		}
+		if startSHA == "" {
+			startSHA = targetBranch.Commit.ID
+		}
+		if baseSHA == "" {
+			baseSHA = targetBranch.Commit.ID
+		}
 	}
 
 	var eg errgroup.Group
 	for _, c := range g.postComments {
 		c := c
 		loc := c.Result.Diagnostic.GetLocation()
-		lnum := int(loc.GetRange().GetStart().GetLine())
-		body := commentutil.MarkdownComment(c)
+		lnum := gitlabCommentLine(c)
 
-		if suggestion := buildSuggestions(c); suggestion != "" {
-			body = body + "\n\n" + suggestion
+		if !gitlabInDiffContext(c) || lnum == 0 {
+			// GitLab cannot create discussions on lines outside the diff context
+			// or without line number. Post them as general notes.
+			body := generalNoteBody(c)
+			if postedNotes[body] {
+				continue
+			}
+			eg.Go(func() error {
+				note := &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.String(body)}
+				if _, _, err := g.cli.Notes.CreateMergeRequestNote(g.projects, g.pr, note, gitlab.WithContext(ctx)); err != nil {
+					return fmt.Errorf("failed to create merge request note: %w", err)
+				}
+				return nil
+			})
+			continue
 		}
 
-		if !c.Result.InDiffFile || lnum == 0 || postedcs.IsPosted(c, lnum, body) {
+		body := buildDiscussionBody(c)
+		// Discussions posted by older reviewdog don't have the marker.
+		if postedcs.IsPosted(c, lnum, body) || postedcs.IsPosted(c, lnum, stripDiscussionMarker(body)) {
 			continue
 		}
 		eg.Go(func() error {
 			pos := &gitlab.NotePosition{
-				StartSHA:     targetBranch.Commit.ID,
-				HeadSHA:      g.sha,
-				BaseSHA:      targetBranch.Commit.ID,
+				StartSHA:     startSHA,
+				HeadSHA:      headSHA,
+				BaseSHA:      baseSHA,
 				PositionType: "text",
 				NewPath:      loc.GetPath(),
 				NewLine:      lnum,
 			}
-			if c.Result.OldPath != "" && c.Result.OldLine != 0 {
+			if loc.GetOld() {
+				// Comment on a deleted line. GitLab requires new_path even for
+				// deleted lines, so leave it as is.
+				pos.NewLine = 0
+			}
+			if oldLine := gitlabOldLine(c, lnum); c.Result.OldPath != "" && oldLine != 0 {
 				pos.OldPath = c.Result.OldPath
-				pos.OldLine = c.Result.OldLine
+				pos.OldLine = oldLine
 			}
+			pos.LineRange = gitlabLineRange(c)
 			discussion := &gitlab.CreateMergeRequestDiscussionOptions{
 				Body:     gitlab.String(body),
 				Position: pos,
 			}
-			_, _, err := g.cli.Discussions.CreateMergeRequestDiscussion(g.projects, g.pr, discussion)
+			_, _, err := g.cli.Discussions.CreateMergeRequestDiscussion(g.projects, g.pr, discussion, gitlab.WithContext(ctx))
 			if err != nil {
 				return fmt.Errorf("failed to create merge request discussion: %w", err)
 			}
@@ -147,6 +215,117 @@ func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Contex
 	return eg.Wait()
 }
 
+// gitlabInDiffContext returns true if GitLab can anchor a diff discussion of
+// the given comment, i.e. the anchor line is in the diff context.
+func gitlabInDiffContext(c *reviewdog.Comment) bool {
+	if c.Result.FirstSuggestionInDiffContext && len(c.Result.Diagnostic.GetSuggestions()) > 0 {
+		return true
+	}
+	return c.Result.InDiffContext
+}
+
+// gitlabCommentLineRange returns the line range of the given comment. If the
+// first suggestion is in diff context, it returns the start line of the
+// suggestion so that the suggestion can be applied from the comment.
+func gitlabCommentLineRange(c *reviewdog.Comment) (start, end int) {
+	if c.Result.FirstSuggestionInDiffContext && len(c.Result.Diagnostic.GetSuggestions()) > 0 {
+		l := int(c.Result.Diagnostic.GetSuggestions()[0].GetRange().GetStart().GetLine())
+		return l, l
+	}
+	r := c.Result.Diagnostic.GetLocation().GetRange()
+	start = int(r.GetStart().GetLine())
+	end = int(r.GetEnd().GetLine())
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// gitlabCommentLine returns the line which GitLab anchors the comment to. It's
+// the end line for multi-line comments as GitLab shows them below the range.
+func gitlabCommentLine(c *reviewdog.Comment) int {
+	_, end := gitlabCommentLineRange(c)
+	return end
+}
+
+// gitlabOldLine returns the line in the old file of the given line of the
+// comment if available. It's 0 for added lines.
+func gitlabOldLine(c *reviewdog.Comment, line int) int {
+	if c.Result.Diagnostic.GetLocation().GetOld() {
+		return line
+	}
+	if oldLine, ok := c.Result.OldLines[line]; ok {
+		return oldLine
+	}
+	// Results may not have OldLines if they are not filtered by diff.
+	r := c.Result.Diagnostic.GetLocation().GetRange()
+	switch line {
+	case int(r.GetStart().GetLine()):
+		return c.Result.OldLine
+	case int(r.GetEnd().GetLine()):
+		return c.Result.OldEndLine
+	}
+	return 0
+}
+
+// gitlabLineRange returns line_range of the position for multi-line comments,
+// otherwise nil.
+func gitlabLineRange(c *reviewdog.Comment) *gitlab.LineRange {
+	start, end := gitlabCommentLineRange(c)
+	if start == end {
+		return nil
+	}
+	path := c.Result.Diagnostic.GetLocation().GetPath()
+	if c.Result.Diagnostic.GetLocation().GetOld() {
+		return &gitlab.LineRange{
+			StartRange: gitlabLinePosition(path, start, 0),
+			EndRange:   gitlabLinePosition(path, end, 0),
+		}
+	}
+	return &gitlab.LineRange{
+		StartRange: gitlabLinePosition(path, gitlabOldLine(c, start), start),
+		EndRange:   gitlabLinePosition(path, gitlabOldLine(c, end), end),
+	}
+}
+
+// gitlabLinePosition returns a position in line_range. Lines which exist only
+// in the new file or in the old file have type "new" or "old" respectively,
+// and unchanged lines have no type.
+func gitlabLinePosition(path string, oldLine, newLine int) *gitlab.LinePosition {
+	p := &gitlab.LinePosition{
+		LineCode: lineCode(path, oldLine, newLine),
+		OldLine:  oldLine,
+		NewLine:  newLine,
+	}
+	switch {
+	case oldLine == 0:
+		p.Type = "new"
+	case newLine == 0:
+		p.Type = "old"
+	}
+	return p
+}
+
+// lineCode returns GitLab line code, which is "<SHA1 of path>_<old line>_<new line>".
+func lineCode(path string, oldLine, newLine int) string {
+	return fmt.Sprintf("%x_%d_%d", sha1.Sum([]byte(path)), oldLine, newLine)
+}
+
+// generalNoteBody returns a body of general note, which has the location of
+// the result as well.
+func generalNoteBody(c *reviewdog.Comment) string {
+	body := commentutil.MarkdownComment(c)
+	loc := c.Result.Diagnostic.GetLocation()
+	if loc.GetPath() == "" {
+		return body
+	}
+	location := loc.GetPath()
+	if lnum := loc.GetRange().GetStart().GetLine(); lnum > 0 {
+		location = fmt.Sprintf("%s:%d", location, lnum)
+	}
+	return fmt.Sprintf("%s\n\n`%s`", body, location)
+}
+
 func listAllMergeRequestDiscussion(cli *gitlab.Client, projectID string, mergeRequest int, opts *gitlab.ListMergeRequestDiscussionsOptions) ([]*gitlab.Discussion, error) {
 	discussions, resp, err := cli.Discussions.ListMergeRequestDiscussions(projectID, mergeRequest, opts)
 	if err != nil {
@@ -188,7 +367,42 @@ func buildSuggestions(c *reviewdog.Comment) string {
 }
 
 func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
-	var sb strings.Builder
+	if c.Result.Diagnostic.GetLocation().GetOld() {
+		return "", errors.New("GitLab cannot apply suggestions to the old file")
+	}
+	start := s.GetRange().GetStart()
+	end := s.GetRange().GetEnd()
+	for l := start.GetLine(); l <= end.GetLine(); l++ {
+		if _, ok := c.Result.SourceLines[int(l)]; !ok {
+			return "", fmt.Errorf("GitLab suggestion range must be in diff. L%d is not in diff", l)
+		}
+	}
+
+	// The suggestion range is relative to the line which the comment is
+	// anchored to.
+	// https://docs.gitlab.com/ee/user/project/merge_requests/reviews/suggestions.html#multi-line-suggestions
+	anchor := gitlabCommentLine(c)
+	if anchor == 0 {
+		anchor = int(start.GetLine())
+	}
+	above, below := anchor-int(start.GetLine()), int(end.GetLine())-anchor
+	if above < 0 || below < 0 {
+		return "", fmt.Errorf("GitLab suggestion range must include the commented line L%d. L%d-L%d",
+			anchor, start.GetLine(), end.GetLine())
+	}
+	lines := "-" + strconv.Itoa(above) + "+" + strconv.Itoa(below)
+
+	txt := s.GetText()
+	// GitLab suggestions replace whole lines, so build the lines from source
+	// lines for suggestions with columns.
+	lineBased := start.GetColumn() == 0 && end.GetColumn() == 0
+	if !lineBased {
+		var err error
+		txt, err = commentutil.NonLineBasedSuggestionText(c.Result.SourceLines, s)
+		if err != nil {
+			return "", err
+		}
+	}
 
 	// we might need to use 4 or more backticks
 	//
@@ -197,16 +411,15 @@ func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, err
 	//
 	// The documentation doesn't explicitly say anything about cases more than 4 backticks,
 	// however it seems to be handled as intended.
-	txt := s.GetText()
 	backticks := commentutil.GetCodeFenceLength(txt)
 
-	lines := strconv.Itoa(int(s.Range.End.Line - s.Range.Start.Line))
-	sb.Grow(backticks + len("suggestion:-0+\n") + len(lines) + len(txt) + len("\n") + backticks)
+	var sb strings.Builder
+	sb.Grow(backticks + len("suggestion:\n") + len(lines) + len(txt) + len("\n") + backticks)
 	commentutil.WriteCodeFence(&sb, backticks)
-	sb.WriteString("suggestion:-0+")
+	sb.WriteString("suggestion:")
 	sb.WriteString(lines)
 	sb.WriteString("\n")
-	if txt != "" {
+	if txt != "" || !lineBased {
 		sb.WriteString(txt)
 		sb.WriteString("\n")
 	}
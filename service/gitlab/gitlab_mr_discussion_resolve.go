@@ -0,0 +1,92 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+// discussionMarkerPrefix is the prefix of the hidden marker in discussions
+// posted by reviewdog. GitLab doesn't render HTML comments.
+const discussionMarkerPrefix = "\n<!-- reviewdog:"
+
+const discussionMarkerSuffix = " -->"
+
+// discussionMarker identifies a discussion posted by reviewdog.
+type discussionMarker struct {
+	Tool        string `json:"tool"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// buildDiscussionBody returns a body of discussion on diff with the marker.
+func buildDiscussionBody(c *reviewdog.Comment) string {
+	body := commentutil.MarkdownComment(c)
+	if suggestion := buildSuggestions(c); suggestion != "" {
+		body = body + "\n\n" + suggestion
+	}
+	// json.Marshal escapes '>', so the marker never contains "-->".
+	m, _ := json.Marshal(&discussionMarker{Tool: c.ToolName, Fingerprint: commentutil.Fingerprint(c)})
+	return body + discussionMarkerPrefix + string(m) + discussionMarkerSuffix
+}
+
+// parseDiscussionMarker returns the marker in the given body if any.
+func parseDiscussionMarker(body string) (*discussionMarker, bool) {
+	i := strings.LastIndex(body, discussionMarkerPrefix)
+	if i < 0 || !strings.HasSuffix(body, discussionMarkerSuffix) {
+		return nil, false
+	}
+	var m discussionMarker
+	raw := strings.TrimSuffix(body[i+len(discussionMarkerPrefix):], discussionMarkerSuffix)
+	if err := json.Unmarshal([]byte(raw), &m); err != nil || m.Fingerprint == "" {
+		return nil, false
+	}
+	return &m, true
+}
+
+func stripDiscussionMarker(body string) string {
+	if _, ok := parseDiscussionMarker(body); !ok {
+		return body
+	}
+	return body[:strings.LastIndex(body, discussionMarkerPrefix)]
+}
+
+// resolveDiscussions resolves discussions posted by reviewdog for the runners
+// whose results are no longer reported, and unresolves ones whose results are
+// reported again if reopen is enabled. It doesn't touch discussions of other
+// runners nor any notes including replies.
+func (g *MergeRequestDiscussionCommenter) resolveDiscussions(ctx context.Context, discussions []*gitlab.Discussion) error {
+	reported := make(map[string]bool, len(g.postComments))
+	for _, c := range g.postComments {
+		reported[commentutil.Fingerprint(c)] = true
+	}
+	for _, d := range discussions {
+		if len(d.Notes) == 0 {
+			continue
+		}
+		first := d.Notes[0]
+		m, ok := parseDiscussionMarker(first.Body)
+		if !ok || !first.Resolvable || !g.runners.Has(m.Tool) {
+			continue
+		}
+		var resolved bool
+		switch {
+		case !reported[m.Fingerprint] && !first.Resolved:
+			resolved = true
+		case reported[m.Fingerprint] && first.Resolved && g.reopen:
+			resolved = false
+		default:
+			continue
+		}
+		opt := &gitlab.ResolveMergeRequestDiscussionOptions{Resolved: gitlab.Bool(resolved)}
+		if _, _, err := g.cli.Discussions.ResolveMergeRequestDiscussion(g.projects, g.pr, d.ID, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("failed to resolve merge request discussion: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+func TestGitLabMergeRequestDiscussionCommenter_resolve(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(tool, path string, line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: tool,
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  path,
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile:    true,
+				InDiffContext: true,
+			},
+		}
+	}
+	stillReported := newComment("tool-a", "a.go", 1, "still reported")
+	// The same result as stillReported, but lines above it have changed.
+	stillReportedMoved := newComment("tool-a", "a.go", 11, "still reported")
+	reportedAgain := newComment("tool-b", "b.go", 2, "reported again")
+	fixed := newComment("tool-a", "a.go", 3, "fixed")
+	fixedAlreadyResolved := newComment("tool-b", "b.go", 4, "fixed and resolved")
+	otherRunner := newComment("tool-c", "c.go", 5, "other runner")
+
+	discussion := func(id string, c *reviewdog.Comment, resolved bool) *gitlab.Discussion {
+		return &gitlab.Discussion{
+			ID: id,
+			Notes: []*gitlab.Note{
+				{
+					Body:       buildDiscussionBody(c),
+					Resolvable: true,
+					Resolved:   resolved,
+					Position: &gitlab.NotePosition{
+						NewPath: c.Result.Diagnostic.GetLocation().GetPath(),
+						NewLine: int(c.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine()),
+					},
+				},
+				{Body: "human reply", Resolvable: true, Resolved: resolved},
+			},
+		}
+	}
+	discussions := []*gitlab.Discussion{
+		discussion("still-reported", stillReported, false),
+		discussion("reported-again", reportedAgain, true),
+		discussion("fixed", fixed, false),
+		discussion("fixed-already-resolved", fixedAlreadyResolved, true),
+		discussion("other-runner", otherRunner, false),
+		{
+			ID: "human",
+			Notes: []*gitlab.Note{
+				{Body: "human discussion", Resolvable: true},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		reopen bool
+		want   map[string]bool
+	}{
+		{
+			reopen: false,
+			want:   map[string]bool{"fixed": true},
+		},
+		{
+			reopen: true,
+			want:   map[string]bool{"fixed": true, "reported-again": false},
+		},
+	} {
+		got := make(map[string]bool)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				if err := json.NewEncoder(w).Encode(discussions); err != nil {
+					t.Fatal(err)
+				}
+			case http.MethodPost:
+				if err := json.NewEncoder(w).Encode(gitlab.Discussion{}); err != nil {
+					t.Fatal(err)
+				}
+			default:
+				t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+			}
+		})
+		mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions/", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut {
+				t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+			}
+			var opt gitlab.ResolveMergeRequestDiscussionOptions
+			if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+				t.Error(err)
+			}
+			got[strings.TrimPrefix(r.URL.Path, "/api/v4/projects/o/r/merge_requests/14/discussions/")] = *opt.Resolved
+			if err := json.NewEncoder(w).Encode(gitlab.Discussion{}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"diff_refs": {"base_sha": "base", "head_sha": "head", "start_sha": "start"}}`))
+		})
+		ts := httptest.NewServer(mux)
+
+		cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha", []string{"tool-a", "tool-b"}, tt.reopen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Flush per runner like project mode. Discussions should be resolved
+		// only after all the runners are flushed.
+		ctx := context.Background()
+		if err := g.Post(ctx, stillReportedMoved); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Errorf("resolved discussions before all runners are flushed: %v", got)
+		}
+		if err := g.Post(ctx, reportedAgain); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("reopen=%v: resolved discussions diff: (-got +want)\n%s", tt.reopen, diff)
+		}
+		ts.Close()
+	}
+}
+
+func TestParseDiscussionMarker(t *testing.T) {
+	c := &reviewdog.Comment{
+		ToolName: "tool --> name",
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{Message: "msg"},
+		},
+	}
+	body := buildDiscussionBody(c)
+	m, ok := parseDiscussionMarker(body)
+	if !ok {
+		t.Fatalf("marker not found: %q", body)
+	}
+	if m.Tool != c.ToolName || m.Fingerprint != commentutil.Fingerprint(c) {
+		t.Errorf("got %+v", m)
+	}
+	if got, want := stripDiscussionMarker(body), "**[tool --> name]** <sub>reported by [reviewdog](https://github.com/reviewdog/reviewdog) :dog:</sub><br>msg"; got != want {
+		t.Errorf("stripDiscussionMarker() = %q, want %q", got, want)
+	}
+	if _, ok := parseDiscussionMarker("human comment"); ok {
+		t.Error("got marker from human comment")
+	}
+}
@@ -3,6 +3,7 @@ package gitlab
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -34,7 +35,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "already commented",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	alreadyCommented2 := &reviewdog.Comment{
@@ -48,7 +50,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "already commented 2",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	newComment1 := &reviewdog.Comment{
@@ -62,7 +65,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "new comment",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	newComment2 := &reviewdog.Comment{
@@ -76,7 +80,8 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "new comment 2",
 			},
-			InDiffFile: true,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	newComment3 := &reviewdog.Comment{
@@ -90,9 +95,49 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				},
 				Message: "new comment 3",
 			},
-			OldPath:    "old_file.go",
-			OldLine:    7,
-			InDiffFile: true,
+			OldPath:       "old_file.go",
+			OldLine:       7,
+			OldEndLine:    7,
+			InDiffFile:    true,
+			InDiffContext: true,
+		},
+	}
+	newCommentOnDeletedLine := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "deleted.go",
+					Range: &rdf.Range{Start: &rdf.Position{
+						Line: 3,
+					}},
+					Old: true,
+				},
+				Message: "new comment on deleted line",
+			},
+			OldPath:       "deleted.go",
+			OldLine:       3,
+			OldEndLine:    3,
+			InDiffFile:    true,
+			InDiffContext: true,
+		},
+	}
+	newMultiLineComment := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "multiline.go",
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 10},
+						End:   &rdf.Position{Line: 12},
+					},
+				},
+				Message: "new multi-line comment",
+			},
+			OldPath:       "multiline.go",
+			OldLine:       9,
+			OldEndLine:    0,
+			InDiffFile:    true,
+			InDiffContext: true,
 		},
 	}
 	commentOutsideDiff := &reviewdog.Comment{
@@ -109,6 +154,20 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 			InDiffFile: false,
 		},
 	}
+	commentOutsideDiffContext := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{
+						Line: 100,
+					}},
+				},
+				Message: "comment outside diff context",
+			},
+			InDiffFile: true,
+		},
+	}
 	commentWithoutLnum := &reviewdog.Comment{
 		Result: &filter.FilteredDiagnostic{
 			Diagnostic: &rdf.Diagnostic{
@@ -127,7 +186,7 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				Location: &rdf.Location{
 					Path: "file3.go",
 					Range: &rdf.Range{Start: &rdf.Position{
-						Line: 14,
+						Line: 13,
 					}},
 				},
 				Message: "new comment with suggestion",
@@ -145,7 +204,9 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 					},
 				},
 			},
-			InDiffFile: true,
+			InDiffFile:                   true,
+			FirstSuggestionInDiffContext: true,
+			SourceLines:                  map[int]string{13: "line13", 14: "line14", 15: "line15"},
 		},
 	}
 
@@ -155,12 +216,17 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 		newComment1,
 		newComment2,
 		newComment3,
+		newCommentOnDeletedLine,
+		newMultiLineComment,
 		commentOutsideDiff,
+		commentOutsideDiffContext,
 		commentWithoutLnum,
 		newCommentWithSuggestion,
 	}
 	var postCalled int32
-	const wantPostCalled = 4
+	const wantPostCalled = 6
+	var notePostCalled int32
+	const wantNotePostCalled = 3
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
@@ -220,27 +286,27 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 			switch got.Position.NewPath {
 			case "file.go":
 				want := &gitlab.CreateMergeRequestDiscussionOptions{
-					Body: gitlab.String(commentutil.MarkdownComment(newComment1)),
+					Body: gitlab.String(buildDiscussionBody(newComment1)),
 					Position: &gitlab.NotePosition{
-						BaseSHA: "xxx", StartSHA: "xxx", HeadSHA: "sha", PositionType: "text", NewPath: "file.go", NewLine: 14},
+						BaseSHA: "base", StartSHA: "start", HeadSHA: "head", PositionType: "text", NewPath: "file.go", NewLine: 14},
 				}
 				if diff := cmp.Diff(got, want); diff != "" {
 					t.Error(diff)
 				}
 			case "file2.go":
 				want := &gitlab.CreateMergeRequestDiscussionOptions{
-					Body: gitlab.String(commentutil.MarkdownComment(newComment2)),
+					Body: gitlab.String(buildDiscussionBody(newComment2)),
 					Position: &gitlab.NotePosition{
-						BaseSHA: "xxx", StartSHA: "xxx", HeadSHA: "sha", PositionType: "text", NewPath: "file2.go", NewLine: 15},
+						BaseSHA: "base", StartSHA: "start", HeadSHA: "head", PositionType: "text", NewPath: "file2.go", NewLine: 15},
 				}
 				if diff := cmp.Diff(got, want); diff != "" {
 					t.Error(diff)
 				}
 			case "new_file.go":
 				want := &gitlab.CreateMergeRequestDiscussionOptions{
-					Body: gitlab.String(commentutil.MarkdownComment(newComment3)),
+					Body: gitlab.String(buildDiscussionBody(newComment3)),
 					Position: &gitlab.NotePosition{
-						BaseSHA: "xxx", StartSHA: "xxx", HeadSHA: "sha", PositionType: "text",
+						BaseSHA: "base", StartSHA: "start", HeadSHA: "head", PositionType: "text",
 						NewPath: "new_file.go", NewLine: 14,
 						OldPath: "old_file.go", OldLine: 7,
 					},
@@ -248,14 +314,48 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 				if diff := cmp.Diff(got, want); diff != "" {
 					t.Error(diff)
 				}
+			case "deleted.go":
+				want := &gitlab.CreateMergeRequestDiscussionOptions{
+					Body: gitlab.String(buildDiscussionBody(newCommentOnDeletedLine)),
+					Position: &gitlab.NotePosition{
+						BaseSHA: "base", StartSHA: "start", HeadSHA: "head", PositionType: "text",
+						NewPath: "deleted.go",
+						OldPath: "deleted.go", OldLine: 3,
+					},
+				}
+				if diff := cmp.Diff(got, want); diff != "" {
+					t.Error(diff)
+				}
+			case "multiline.go":
+				want := &gitlab.CreateMergeRequestDiscussionOptions{
+					Body: gitlab.String(buildDiscussionBody(newMultiLineComment)),
+					Position: &gitlab.NotePosition{
+						BaseSHA: "base", StartSHA: "start", HeadSHA: "head", PositionType: "text",
+						NewPath: "multiline.go", NewLine: 12,
+						LineRange: &gitlab.LineRange{
+							StartRange: &gitlab.LinePosition{
+								LineCode: "e6286f3490264311e897227a1cf73ed8d639918f_9_10", OldLine: 9, NewLine: 10,
+							},
+							EndRange: &gitlab.LinePosition{
+								LineCode: "e6286f3490264311e897227a1cf73ed8d639918f_0_12", Type: "new", NewLine: 12,
+							},
+						},
+					},
+				}
+				if diff := cmp.Diff(got, want); diff != "" {
+					t.Error(diff)
+				}
 			case "file3.go":
 				suggestions := buildSuggestions(newCommentWithSuggestion)
 				bodyExpected := commentutil.MarkdownComment(newCommentWithSuggestion) + "\n\n" + suggestions
+				if !strings.HasPrefix(*got.Body, bodyExpected) {
+					t.Errorf("got unexpected body: %q", *got.Body)
+				}
 
 				want := &gitlab.CreateMergeRequestDiscussionOptions{
-					Body: gitlab.String(bodyExpected),
+					Body: gitlab.String(buildDiscussionBody(newCommentWithSuggestion)),
 					Position: &gitlab.NotePosition{
-						BaseSHA: "xxx", StartSHA: "xxx", HeadSHA: "sha", PositionType: "text", NewPath: "file3.go", NewLine: 14},
+						BaseSHA: "base", StartSHA: "start", HeadSHA: "head", PositionType: "text", NewPath: "file3.go", NewLine: 14},
 				}
 				if diff := cmp.Diff(got, want); diff != "" {
 					t.Error(diff)
@@ -274,13 +374,27 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 		if r.Method != http.MethodGet {
 			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
 		}
-		w.Write([]byte(`{"target_project_id": 14, "target_branch": "test-branch"}`))
+		w.Write([]byte(`{"diff_refs": {"base_sha": "base", "head_sha": "head", "start_sha": "start"}}`))
 	})
-	mux.HandleFunc("/api/v4/projects/14/repository/branches/test-branch", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/notes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
 			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
 		}
-		w.Write([]byte(`{"commit": {"id": "xxx"}}`))
+		atomic.AddInt32(&notePostCalled, 1)
+		got := new(gitlab.CreateMergeRequestNoteOptions)
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Error(err)
+		}
+		switch body := got.Body; {
+		case *body == commentutil.MarkdownComment(commentOutsideDiff)+"\n\n`path.go:14`":
+		case *body == commentutil.MarkdownComment(commentOutsideDiffContext)+"\n\n`file.go:100`":
+		case *body == commentutil.MarkdownComment(commentWithoutLnum)+"\n\n`path.go`":
+		default:
+			t.Errorf("got unexpected note: %q", *body)
+		}
+		if err := json.NewEncoder(w).Encode(gitlab.Note{}); err != nil {
+			t.Fatal(err)
+		}
 	})
 
 	ts := httptest.NewServer(mux)
@@ -291,7 +405,7 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 		t.Fatal(err)
 	}
 
-	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha")
+	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha", nil, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -307,6 +421,105 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 	if postCalled != wantPostCalled {
 		t.Errorf("%d discussions posted, but want %d", postCalled, wantPostCalled)
 	}
+	if notePostCalled != wantNotePostCalled {
+		t.Errorf("%d notes posted, but want %d", notePostCalled, wantNotePostCalled)
+	}
+}
+
+func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_no_diff_refs(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Message: "new comment",
+			},
+			InDiffFile:    true,
+			InDiffContext: true,
+		},
+	}
+	var postCalled int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`[]`))
+		case http.MethodPost:
+			atomic.AddInt32(&postCalled, 1)
+			got := new(gitlab.CreateMergeRequestDiscussionOptions)
+			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+				t.Error(err)
+			}
+			want := &gitlab.NotePosition{
+				BaseSHA: "target", StartSHA: "target", HeadSHA: "sha", PositionType: "text", NewPath: "file.go", NewLine: 14}
+			if diff := cmp.Diff(got.Position, want); diff != "" {
+				t.Error(diff)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"target_project_id": 14, "target_branch": "main", "diff_refs": {}}`))
+	})
+	mux.HandleFunc("/api/v4/projects/14/repository/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit": {"id": "target"}}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Post(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if postCalled != 1 {
+		t.Errorf("%d discussions posted, but want 1", postCalled)
+	}
+}
+
+func TestGitLabOldLine(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 10}, End: &rdf.Position{Line: 12}},
+				},
+				Suggestions: []*rdf.Suggestion{{
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}, End: &rdf.Position{Line: 14}},
+				}},
+			},
+			OldPath:    "file.go",
+			OldLine:    8,
+			OldEndLine: 0,
+			// Line 11 and 12 are added.
+			OldLines: map[int]int{10: 8, 11: 0, 12: 0, 14: 10},
+		},
+	}
+	for line, want := range map[int]int{10: 8, 11: 0, 12: 0, 14: 10, 20: 0} {
+		if got := gitlabOldLine(c, line); got != want {
+			t.Errorf("gitlabOldLine(c, %d) = %d, want %d", line, got, want)
+		}
+	}
 }
 
 func TestBuildSuggestions(t *testing.T) {
@@ -376,6 +589,99 @@ func TestBuildSuggestions(t *testing.T) {
 				"",
 			}, "\n"),
 		},
+		{
+			in: &reviewdog.Comment{
+				ToolName: "tool-name",
+				Result: &filter.FilteredDiagnostic{
+					Diagnostic: &rdf.Diagnostic{
+						Message: "multi-line comment anchored to the end line",
+						Location: &rdf.Location{
+							Path: "file.go",
+							Range: &rdf.Range{
+								Start: &rdf.Position{Line: 10},
+								End:   &rdf.Position{Line: 12},
+							},
+						},
+						Suggestions: []*rdf.Suggestion{
+							buildTestsSuggestion("line1-fixed\nline2-fixed", 10, 12),
+						},
+					},
+					SourceLines: testSourceLines(),
+				},
+			},
+			want: strings.Join([]string{
+				"```suggestion:-2+0",
+				"line1-fixed",
+				"line2-fixed",
+				"```",
+				"",
+			}, "\n"),
+		},
+		{
+			in: &reviewdog.Comment{
+				ToolName: "tool-name",
+				Result: &filter.FilteredDiagnostic{
+					Diagnostic: &rdf.Diagnostic{
+						Message: "comment anchored to the start line of the suggestion",
+						Location: &rdf.Location{
+							Path:  "file.go",
+							Range: &rdf.Range{Start: &rdf.Position{Line: 9}},
+						},
+						Suggestions: []*rdf.Suggestion{
+							buildTestsSuggestion("line1-fixed\nline2-fixed", 10, 11),
+						},
+					},
+					FirstSuggestionInDiffContext: true,
+					SourceLines:                  testSourceLines(),
+				},
+			},
+			want: strings.Join([]string{
+				"```suggestion:-0+1",
+				"line1-fixed",
+				"line2-fixed",
+				"```",
+				"",
+			}, "\n"),
+		},
+		{
+			in: buildTestComment(
+				"non-line based suggestion",
+				[]*rdf.Suggestion{
+					{
+						Text: "replaced",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 10, Column: 3},
+							End:   &rdf.Position{Line: 11, Column: 5},
+						},
+					},
+				},
+			),
+			want: strings.Join([]string{
+				"```suggestion:-0+1",
+				"lireplaced11",
+				"```",
+				"",
+			}, "\n"),
+		},
+		{
+			in: buildTestComment(
+				"non-line based suggestion which removes the line content",
+				[]*rdf.Suggestion{
+					{
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 10, Column: 1},
+							End:   &rdf.Position{Line: 10, Column: 7},
+						},
+					},
+				},
+			),
+			want: strings.Join([]string{
+				"```suggestion:-0+0",
+				"",
+				"```",
+				"",
+			}, "\n"),
+		},
 	}
 	for _, tt := range tests {
 		suggestion := buildSuggestions(tt.in)
@@ -430,6 +736,15 @@ func TestBuildSuggestionsInvalid(t *testing.T) {
 				"",
 			}, "\n"),
 		},
+		{
+			in: buildTestComment(
+				"suggestion outside diff",
+				[]*rdf.Suggestion{
+					buildTestsSuggestion("line1-fixed\nline2-fixed", 30, 31),
+				},
+			),
+			want: "<details><summary>reviewdog suggestion error</summary>GitLab suggestion range must be in diff. L31 is not in diff</details>\n",
+		},
 	}
 	for _, tt := range tests {
 		suggestion := buildSuggestions(tt.in)
@@ -461,6 +776,16 @@ func buildTestComment(message string, suggestions []*rdf.Suggestion) *reviewdog.
 				Message:     message,
 				Suggestions: suggestions,
 			},
+			SourceLines: testSourceLines(),
 		},
 	}
 }
+
+// testSourceLines returns source lines from L1 to L30 in diff.
+func testSourceLines() map[int]string {
+	lines := make(map[int]string, 30)
+	for i := 1; i <= 30; i++ {
+		lines[i] = fmt.Sprintf("line%d", i)
+	}
+	return lines
+}
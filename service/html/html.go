@@ -0,0 +1,354 @@
+// Package html provides a comment service which writes results as a static
+// HTML report.
+package html
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.BulkCommentService = &Report{}
+
+// contextLines is the number of lines shown around results in file views.
+const contextLines = 3
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var tmpl = template.Must(template.New("report").Parse(reportTemplate))
+
+// Report is a comment service which writes results as a self-contained HTML
+// report, which has counts of results per tool and severity, a table of the
+// results and file views with the results and their suggestions.
+type Report struct {
+	path    string
+	build   *cienv.BuildInfo
+	runners []string
+
+	mu       sync.Mutex
+	comments []*reviewdog.Comment
+
+	now      func() time.Time
+	readFile func(string) ([]byte, error)
+}
+
+// NewReport returns a new Report service which writes the report to the given
+// path. build can be nil if build information is not available. Runners
+// without results are shown in the report as well.
+func NewReport(path string, build *cienv.BuildInfo, runners []string) *Report {
+	r := &Report{path: path, build: build, now: time.Now, readFile: os.ReadFile}
+	for _, runner := range runners {
+		if runner != "" {
+			r.runners = append(r.runners, runner)
+		}
+	}
+	return r
+}
+
+// Post accepts a comment and holds it as a result of the report.
+func (r *Report) Post(_ context.Context, c *reviewdog.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.comments = append(r.comments, c)
+	return nil
+}
+
+// Flush writes the report with all the results posted so far. It's called per
+// tool in project mode, so it overwrites the report every time.
+func (r *Report) Flush(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r.buildData()); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	if err := os.WriteFile(r.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+type reportData struct {
+	Build       *cienv.BuildInfo
+	GeneratedAt string
+	Summary     *summary
+	Tools       []*summary
+	Results     []*result
+	Files       []*file
+}
+
+// summary is counts of results per severity of a tool, or of all the tools.
+type summary struct {
+	Name string
+	serviceutil.SeveritySummary
+}
+
+type result struct {
+	ID       string
+	FileID   string
+	Tool     string
+	Severity string
+	// Rank orders results by severity in the table.
+	Rank        int
+	Path        string
+	Line        int
+	Column      int
+	Code        string
+	CodeURL     string
+	Message     string
+	Suggestions []*suggestion
+
+	comment *reviewdog.Comment
+}
+
+// Location returns the location of the result in <path>:<line>:<column>.
+func (r *result) Location() string {
+	s := r.Path
+	if r.Line > 0 {
+		s += fmt.Sprintf(":%d", r.Line)
+		if r.Column > 0 {
+			s += fmt.Sprintf(":%d", r.Column)
+		}
+	}
+	return s
+}
+
+// suggestion is a suggestion as a diff of the source lines.
+type suggestion struct {
+	Old []*line
+	New []string
+}
+
+type file struct {
+	ID   string
+	Path string
+	// Results don't have lines in Hunks.
+	Results []*result
+	Hunks   [][]*line
+
+	all []*result
+}
+
+type line struct {
+	Num       int
+	Text      string
+	Highlight bool
+	// Results are shown below the line.
+	Results []*result
+}
+
+func (r *Report) buildData() *reportData {
+	data := &reportData{
+		Build:       r.build,
+		GeneratedAt: r.now().UTC().Format(time.RFC3339),
+		Summary:     &summary{},
+	}
+
+	tools := make(map[string]*summary)
+	for _, name := range r.runners {
+		tools[name] = &summary{Name: name}
+	}
+	comments := make([]*reviewdog.Comment, len(r.comments))
+	copy(comments, r.comments)
+	sort.SliceStable(comments, func(i, j int) bool {
+		li, lj := comments[i].Result.Diagnostic.GetLocation(), comments[j].Result.Diagnostic.GetLocation()
+		if li.GetPath() != lj.GetPath() {
+			return li.GetPath() < lj.GetPath()
+		}
+		si, sj := li.GetRange().GetStart(), lj.GetRange().GetStart()
+		if si.GetLine() != sj.GetLine() {
+			return si.GetLine() < sj.GetLine()
+		}
+		return si.GetColumn() < sj.GetColumn()
+	})
+
+	files := make(map[string]*file)
+	for i, c := range comments {
+		d := c.Result.Diagnostic
+		loc := d.GetLocation()
+		res := &result{
+			ID:       fmt.Sprintf("r%d", i+1),
+			Tool:     c.ToolName,
+			Severity: serviceutil.SeverityName(d.GetSeverity()),
+			Rank:     severityRank(d.GetSeverity()),
+			Path:     loc.GetPath(),
+			Line:     int(loc.GetRange().GetStart().GetLine()),
+			Column:   int(loc.GetRange().GetStart().GetColumn()),
+			Code:     d.GetCode().GetValue(),
+			CodeURL:  d.GetCode().GetUrl(),
+			Message:  d.GetMessage(),
+			comment:  c,
+		}
+		data.Results = append(data.Results, res)
+		data.Summary.Add(d.GetSeverity())
+		if tools[c.ToolName] == nil {
+			tools[c.ToolName] = &summary{Name: c.ToolName}
+		}
+		tools[c.ToolName].Add(d.GetSeverity())
+
+		if res.Path == "" {
+			for _, s := range d.GetSuggestions() {
+				res.Suggestions = append(res.Suggestions, buildSuggestion(nil, s))
+			}
+			continue
+		}
+		f := files[res.Path]
+		if f == nil {
+			f = &file{ID: fmt.Sprintf("f%d", len(data.Files)+1), Path: res.Path}
+			files[res.Path] = f
+			data.Files = append(data.Files, f)
+		}
+		res.FileID = f.ID
+		f.all = append(f.all, res)
+	}
+	for _, f := range data.Files {
+		r.buildHunks(f)
+	}
+
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data.Tools = append(data.Tools, tools[name])
+	}
+	return data
+}
+
+// buildHunks builds hunks of the source lines around the results of the
+// file. Results without available source lines are listed in f.Results.
+func (r *Report) buildHunks(f *file) {
+	src := r.source(f.Path, f.all)
+	lines := make(map[int]*line)
+	shown := make(map[int]bool)
+	for _, res := range f.all {
+		c := res.comment
+		loc := c.Result.Diagnostic.GetLocation()
+		start := int(loc.GetRange().GetStart().GetLine())
+		end := int(loc.GetRange().GetEnd().GetLine())
+		if end < start {
+			end = start
+		}
+		for _, s := range c.Result.Diagnostic.GetSuggestions() {
+			res.Suggestions = append(res.Suggestions, buildSuggestion(src, s))
+		}
+		last, ok := lastAvailableLine(src, loc, start, end)
+		if !ok {
+			f.Results = append(f.Results, res)
+			continue
+		}
+		for lnum := start; lnum <= end; lnum++ {
+			if text, ok := src[lnum]; ok {
+				l := getLine(lines, lnum, text)
+				l.Highlight = true
+			}
+		}
+		for lnum := start - contextLines; lnum <= end+contextLines; lnum++ {
+			if _, ok := src[lnum]; ok {
+				shown[lnum] = true
+			}
+		}
+		l := getLine(lines, last, src[last])
+		l.Results = append(l.Results, res)
+	}
+
+	nums := make([]int, 0, len(shown))
+	for lnum := range shown {
+		nums = append(nums, lnum)
+	}
+	sort.Ints(nums)
+	var hunk []*line
+	for i, lnum := range nums {
+		if i > 0 && lnum != nums[i-1]+1 {
+			f.Hunks = append(f.Hunks, hunk)
+			hunk = nil
+		}
+		hunk = append(hunk, getLine(lines, lnum, src[lnum]))
+	}
+	if len(hunk) > 0 {
+		f.Hunks = append(f.Hunks, hunk)
+	}
+}
+
+// source returns lines of the file by line number. It uses source lines in
+// diff of the results if the file cannot be read.
+func (r *Report) source(path string, results []*result) map[int]string {
+	src := make(map[int]string)
+	if b, err := r.readFile(path); err == nil {
+		for i, l := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+			src[i+1] = strings.TrimSuffix(l, "\r")
+		}
+		return src
+	}
+	for _, res := range results {
+		for lnum, l := range res.comment.Result.SourceLines {
+			src[lnum] = l
+		}
+	}
+	return src
+}
+
+// lastAvailableLine returns the last line of the range which is available in
+// the source. Results on the old file are not shown in the source as the file
+// may have been changed.
+func lastAvailableLine(src map[int]string, loc *rdf.Location, start, end int) (int, bool) {
+	if start == 0 || loc.GetOld() {
+		return 0, false
+	}
+	for lnum := end; lnum >= start; lnum-- {
+		if _, ok := src[lnum]; ok {
+			return lnum, true
+		}
+	}
+	return 0, false
+}
+
+func getLine(lines map[int]*line, lnum int, text string) *line {
+	l := lines[lnum]
+	if l == nil {
+		l = &line{Num: lnum, Text: text}
+		lines[lnum] = l
+	}
+	return l
+}
+
+// buildSuggestion returns the suggestion as a diff. Old is empty if source
+// lines are not available.
+func buildSuggestion(src map[int]string, s *rdf.Suggestion) *suggestion {
+	sd := serviceutil.BuildSuggestionDiff(s, func(lnum int) (string, bool) {
+		text, ok := src[lnum]
+		return text, ok
+	})
+	sg := &suggestion{New: sd.New}
+	for i, text := range sd.Old {
+		sg.Old = append(sg.Old, &line{Num: sd.StartLine + i, Text: text})
+	}
+	return sg
+}
+
+func severityRank(s rdf.Severity) int {
+	switch s {
+	case rdf.Severity_ERROR:
+		return 1
+	case rdf.Severity_WARNING:
+		return 2
+	case rdf.Severity_INFO:
+		return 3
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,181 @@
+package html
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var testSource = map[string]string{
+	"a.go": "package a\n\nimport \"fmt\"\n\nfunc f() {\n\tx := 1\n}\n\nfunc g() {}\n\nfunc h() {}\n\nfunc i() {}\n\nfunc j() {}\n\nfunc k() {}\n",
+}
+
+func testReport(path string) *Report {
+	r := NewReport(path, &cienv.BuildInfo{Owner: "haya14busa", Repo: "reviewdog", SHA: "sha1"}, []string{"golint", "vet", "empty"})
+	r.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	r.readFile = func(path string) ([]byte, error) {
+		if s, ok := testSource[path]; ok {
+			return []byte(s), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	return r
+}
+
+var testComments = []*reviewdog.Comment{
+	{
+		ToolName: "vet",
+		Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+			Location: &rdf.Location{Path: "a.go", Range: &rdf.Range{
+				Start: &rdf.Position{Line: 15, Column: 6},
+			}},
+			Message:  "func j is unused",
+			Severity: rdf.Severity_WARNING,
+		}},
+	},
+	{
+		ToolName: "vet",
+		Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+			Location: &rdf.Location{Path: "a.go", Range: &rdf.Range{
+				Start: &rdf.Position{Line: 6, Column: 2},
+				End:   &rdf.Position{Line: 6, Column: 3},
+			}},
+			Message:  "x declared but not used <script>",
+			Severity: rdf.Severity_ERROR,
+			Code:     &rdf.Code{Value: "SA4006", Url: "https://example.com/SA4006"},
+			Suggestions: []*rdf.Suggestion{{
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 6, Column: 2},
+					End:   &rdf.Position{Line: 6, Column: 3},
+				},
+				Text: "_",
+			}},
+		}},
+	},
+	{
+		ToolName: "golint",
+		Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+			Location: &rdf.Location{Path: "a.go", Range: &rdf.Range{
+				Start: &rdf.Position{Line: 3},
+			}},
+			Message: "unused import",
+		}},
+	},
+	{
+		ToolName: "golint",
+		Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+			Location: &rdf.Location{Path: "b.go"},
+			Message:  "missing license header",
+			Severity: rdf.Severity_INFO,
+		}},
+	},
+}
+
+func TestReport_buildData(t *testing.T) {
+	r := testReport("")
+	for _, c := range testComments {
+		if err := r.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := r.buildData()
+
+	wantTools := []*summary{
+		{Name: "empty"},
+		{Name: "golint", SeveritySummary: serviceutil.SeveritySummary{Total: 2, Info: 1, Unknown: 1}},
+		{Name: "vet", SeveritySummary: serviceutil.SeveritySummary{Total: 2, Error: 1, Warning: 1}},
+	}
+	if diff := cmp.Diff(wantTools, data.Tools); diff != "" {
+		t.Errorf("tools has diff:\n%s", diff)
+	}
+	wantSummary := &summary{SeveritySummary: serviceutil.SeveritySummary{Total: 4, Error: 1, Warning: 1, Info: 1, Unknown: 1}}
+	if diff := cmp.Diff(wantSummary, data.Summary); diff != "" {
+		t.Errorf("summary has diff:\n%s", diff)
+	}
+
+	var locations []string
+	for _, res := range data.Results {
+		locations = append(locations, res.ID+" "+res.Location())
+	}
+	wantLocations := []string{"r1 a.go:3", "r2 a.go:6:2", "r3 a.go:15:6", "r4 b.go"}
+	if diff := cmp.Diff(wantLocations, locations); diff != "" {
+		t.Errorf("results has diff:\n%s", diff)
+	}
+
+	if len(data.Files) != 2 {
+		t.Fatalf("got %d files, want 2", len(data.Files))
+	}
+	a, b := data.Files[0], data.Files[1]
+	// Lines with results and their context.
+	var hunks [][]int
+	for _, hunk := range a.Hunks {
+		var nums []int
+		for _, l := range hunk {
+			nums = append(nums, l.Num)
+		}
+		hunks = append(hunks, nums)
+	}
+	wantHunks := [][]int{{1, 2, 3, 4, 5, 6, 7, 8, 9}, {12, 13, 14, 15, 16, 17}}
+	if diff := cmp.Diff(wantHunks, hunks); diff != "" {
+		t.Errorf("hunks has diff:\n%s", diff)
+	}
+	if l := a.Hunks[0][5]; !l.Highlight || len(l.Results) != 1 || l.Results[0].ID != "r2" {
+		t.Errorf("line 6 doesn't have the result: %+v", l)
+	}
+	if len(b.Hunks) != 0 || len(b.Results) != 1 || b.Results[0].ID != "r4" {
+		t.Errorf("b.go should have the result without source lines: %+v", b)
+	}
+
+	sg := data.Results[1].Suggestions
+	if len(sg) != 1 || len(sg[0].Old) != 1 || sg[0].Old[0].Text != "\tx := 1" {
+		t.Fatalf("unexpected suggestion: %+v", sg)
+	}
+	if diff := cmp.Diff([]string{"\t_ := 1"}, sg[0].New); diff != "" {
+		t.Errorf("suggestion has diff:\n%s", diff)
+	}
+}
+
+func TestReport_Flush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	r := testReport(path)
+	for _, c := range testComments {
+		if err := r.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		`<title>reviewdog report - haya14busa/reviewdog</title>`,
+		`generated at 2026-01-02T03:04:05Z`,
+		`<tr><td>vet</td><td class="num">2</td><td class="num">1</td><td class="num">1</td><td class="num">0</td><td class="num">0</td></tr>`,
+		`<option value="empty">empty</option>`,
+		`<a href="https://example.com/SA4006">SA4006</a>`,
+		`<a href="#r2">a.go:6:2</a>`,
+		`<div class="annotation error" id="r2">`,
+		`x declared but not used &lt;script&gt;`,
+		`<pre class="del">   6 - 	x := 1</pre>`,
+		`<pre class="add">     + 	_ := 1</pre>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report doesn't contain %q", want)
+		}
+	}
+}
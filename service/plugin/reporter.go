@@ -0,0 +1,266 @@
+// Package plugin provides reviewdog services backed by external executables.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+var _ reviewdog.BulkCommentService = &Reporter{}
+var _ reviewdog.DiffService = &Reporter{}
+
+// ReporterPrefix is the prefix of reporter plugin executables.
+const ReporterPrefix = "reviewdog-reporter-"
+
+// ProtocolVersion is the version of reporter plugin protocol.
+const ProtocolVersion = 1
+
+// Subcommands of reporter plugins.
+const (
+	handshakeCommand = "handshake"
+	diffCommand      = "diff"
+	reportCommand    = "report"
+)
+
+// Header is the first line of stdin of all the subcommands.
+type Header struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Build           *Build `json:"build,omitempty"`
+	// Tools are the names of tools of the results. Only for report.
+	Tools []string `json:"tools,omitempty"`
+}
+
+// Build is build information from CI environment.
+type Build struct {
+	Owner       string `json:"owner,omitempty"`
+	Repo        string `json:"repo,omitempty"`
+	SHA         string `json:"sha,omitempty"`
+	PullRequest int    `json:"pull_request,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+}
+
+// Handshake is the response of handshake subcommand.
+type Handshake struct {
+	ProtocolVersion int `json:"protocol_version"`
+	// Diff requests reviewdog to get diff from diff subcommand, whose paths are
+	// stripped by Strip.
+	Diff  bool `json:"diff,omitempty"`
+	Strip int  `json:"strip,omitempty"`
+}
+
+// Result is a filtered result in stdin of report subcommand. Diagnostic is
+// encoded in rdjson format.
+type Result struct {
+	Diagnostic    *rdf.Diagnostic
+	InDiffFile    bool
+	InDiffContext bool
+}
+
+// MarshalJSON encodes the result with the diagnostic in rdjson format.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	b, err := protojson.Marshal(r.Diagnostic)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		Diagnostic    json.RawMessage `json:"diagnostic"`
+		InDiffFile    bool            `json:"in_diff_file"`
+		InDiffContext bool            `json:"in_diff_context"`
+	}{b, r.InDiffFile, r.InDiffContext})
+}
+
+// Reporter is a comment service which passes results to a reporter plugin
+// executable, reviewdog-reporter-<name> in $PATH. It can be a diff service as
+// well if the plugin provides diff.
+//
+// Protocol:
+//  All the subcommands receive Header as JSON in the first line of stdin.
+//  "<plugin> handshake" prints Handshake as JSON to stdout.
+//  "<plugin> diff" prints diff to stdout if the handshake requests it.
+//  "<plugin> report" receives a Result as JSON per line after the header.
+//  Source names of the diagnostics are the tool names. Non-zero exit code
+//  fails the report with stderr of the plugin.
+type Reporter struct {
+	path      string
+	build     *Build
+	handshake *Handshake
+	// stderr receives stderr of the plugin and stdout of report subcommand.
+	stderr io.Writer
+
+	mu       sync.Mutex
+	comments *commentutil.ToolComments
+}
+
+// NewReporter finds the reporter plugin of the name in $PATH and does a
+// handshake with it. build can be nil if build information is not available.
+// The plugin is invoked once all the runners are flushed, or on every Flush
+// if runners are not given.
+func NewReporter(ctx context.Context, name string, build *cienv.BuildInfo, runners []string, stderr io.Writer) (*Reporter, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid reporter plugin name: %q", name)
+	}
+	path, err := exec.LookPath(ReporterPrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("reporter plugin %q is not found: %w", name, err)
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+	r := &Reporter{
+		path:     path,
+		stderr:   stderr,
+		comments: commentutil.NewToolComments(runners),
+	}
+	if build != nil {
+		r.build = &Build{
+			Owner:       build.Owner,
+			Repo:        build.Repo,
+			SHA:         build.SHA,
+			PullRequest: build.PullRequest,
+			Branch:      build.Branch,
+		}
+	}
+
+	out, err := r.run(ctx, handshakeCommand, r.header(nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	var hs Handshake
+	if err := json.Unmarshal(out, &hs); err != nil {
+		return nil, fmt.Errorf("reporter plugin %q returned invalid handshake: %w", name, err)
+	}
+	if hs.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("reporter plugin %q uses unsupported protocol version %d (want %d)",
+			name, hs.ProtocolVersion, ProtocolVersion)
+	}
+	r.handshake = &hs
+	return r, nil
+}
+
+// HasDiff returns true if the plugin provides diff.
+func (r *Reporter) HasDiff() bool {
+	return r.handshake.Diff
+}
+
+// Diff returns diff from the plugin.
+func (r *Reporter) Diff(ctx context.Context) ([]byte, error) {
+	if !r.HasDiff() {
+		return nil, errors.New("reporter plugin doesn't provide diff")
+	}
+	return r.run(ctx, diffCommand, r.header(nil), nil)
+}
+
+// Strip returns the strip of diff from the handshake.
+func (r *Reporter) Strip() int {
+	return r.handshake.Strip
+}
+
+// Post accepts a comment and holds it. Flush method actually passes results
+// to the plugin.
+func (r *Reporter) Post(_ context.Context, c *reviewdog.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.comments.Add(c)
+	return nil
+}
+
+// Flush passes the held results to the plugin once all the runners are
+// flushed.
+func (r *Reporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.comments.Flush() {
+		return nil
+	}
+
+	tools := r.comments.Tools()
+	var body bytes.Buffer
+	for _, tool := range tools {
+		for _, c := range r.comments.Comments(tool) {
+			d := proto.Clone(c.Result.Diagnostic).(*rdf.Diagnostic)
+			if d.GetSource().GetName() == "" {
+				d.Source = &rdf.Source{Name: tool, Url: d.GetSource().GetUrl()}
+			}
+			b, err := json.Marshal(&Result{
+				Diagnostic:    d,
+				InDiffFile:    c.Result.InDiffFile,
+				InDiffContext: c.Result.InDiffContext,
+			})
+			if err != nil {
+				return err
+			}
+			body.Write(b)
+			body.WriteByte('\n')
+		}
+	}
+	r.comments.Reset()
+
+	out, err := r.run(ctx, reportCommand, r.header(tools), &body)
+	if len(out) > 0 {
+		r.stderr.Write(out)
+	}
+	return err
+}
+
+func (r *Reporter) header(tools []string) *Header {
+	return &Header{ProtocolVersion: ProtocolVersion, Build: r.build, Tools: tools}
+}
+
+// run runs the subcommand of the plugin with the header and body on stdin,
+// and returns stdout. Stderr of the plugin is passed to r.stderr, and it's
+// included in the error if the plugin fails.
+func (r *Reporter) run(ctx context.Context, subcommand string, header *Header, body io.Reader) ([]byte, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	stdin := io.Reader(bytes.NewReader(append(h, '\n')))
+	if body != nil {
+		stdin = io.MultiReader(stdin, body)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.path, subcommand)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.MultiWriter(&stderr, r.stderr)
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(lastLines(stderr.String(), 20))
+		if msg == "" {
+			return stdout.Bytes(), fmt.Errorf("reporter plugin %s %s failed: %w", r.path, subcommand, err)
+		}
+		return stdout.Bytes(), fmt.Errorf("reporter plugin %s %s failed: %w: %s", r.path, subcommand, err, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	var lines []string
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
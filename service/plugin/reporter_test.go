@@ -0,0 +1,157 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// setupPlugin installs a reporter plugin script to a temporary $PATH.
+func setupPlugin(t *testing.T, name, script string) (dir string) {
+	t.Helper()
+	dir = t.TempDir()
+	path := filepath.Join(dir, ReporterPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func newComment(tool, path, msg string) *reviewdog.Comment {
+	return &reviewdog.Comment{
+		ToolName: tool,
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  path,
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Message: msg,
+			},
+		},
+	}
+}
+
+func TestReporter(t *testing.T) {
+	dir := setupPlugin(t, "test", `
+case "$1" in
+handshake) cat > "$(dirname "$0")/handshake.in"; echo '{"protocol_version": 1, "diff": true, "strip": 1}' ;;
+diff) cat > "$(dirname "$0")/diff.in"; echo 'diff --git a/a.go b/a.go' ;;
+report) cat > "$(dirname "$0")/report.in"; echo 'posted'; echo 'log' >&2 ;;
+esac
+`)
+	ctx := context.Background()
+	var stderr bytes.Buffer
+	build := &cienv.BuildInfo{Owner: "o", Repo: "r", SHA: "sha", PullRequest: 14}
+	r, err := NewReporter(ctx, "test", build, []string{"tool1", "tool2", "tool3"}, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.HasDiff() || r.Strip() != 1 {
+		t.Errorf("HasDiff() = %v, Strip() = %d, want true and 1", r.HasDiff(), r.Strip())
+	}
+	d, err := r.Diff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(d), "diff --git a/a.go b/a.go\n"; got != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+
+	r.Post(ctx, newComment("tool2", "b.go", "msg2"))
+	c := newComment("tool1", "a.go", "msg1")
+	c.Result.InDiffFile, c.Result.InDiffContext = true, true
+	r.Post(ctx, c)
+	for i := 0; i < 2; i++ {
+		if err := r.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "report.in")); err == nil {
+			t.Fatal("plugin is invoked before all the runners are flushed")
+		}
+	}
+	if err := r.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	header := `{"protocol_version":1,"build":{"owner":"o","repo":"r","sha":"sha","pull_request":14}}` + "\n"
+	for _, f := range []string{"handshake.in", "diff.in"} {
+		if got := readFile(t, filepath.Join(dir, f)); got != header {
+			t.Errorf("stdin of %s = %q, want %q", f, got, header)
+		}
+	}
+	got := strings.Split(readFile(t, filepath.Join(dir, "report.in")), "\n")
+	want := []string{
+		`{"protocol_version":1,"build":{"owner":"o","repo":"r","sha":"sha","pull_request":14},"tools":["tool1","tool2","tool3"]}`,
+		`{"diagnostic":{"message":"msg1","location":{"path":"a.go","range":{"start":{"line":14}}},"source":{"name":"tool1"}},"in_diff_file":true,"in_diff_context":true}`,
+		`{"diagnostic":{"message":"msg2","location":{"path":"b.go","range":{"start":{"line":14}}},"source":{"name":"tool2"}},"in_diff_file":false,"in_diff_context":false}`,
+		``,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("stdin of report = %q, want %q", got, want)
+	}
+	for i := range want {
+		// protojson doesn't guarantee stable spaces.
+		if g := strings.ReplaceAll(got[i], " ", ""); g != want[i] {
+			t.Errorf("line %d of report stdin = %q, want %q", i, g, want[i])
+		}
+	}
+	if got, want := stderr.String(), "log\nposted\n"; got != want {
+		t.Errorf("stderr = %q, want %q", got, want)
+	}
+}
+
+func TestReporter_failure(t *testing.T) {
+	setupPlugin(t, "fail", `
+case "$1" in
+handshake) echo '{"protocol_version": 1}' ;;
+report) echo 'failed to post comments' >&2; exit 3 ;;
+esac
+`)
+	ctx := context.Background()
+	r, err := NewReporter(ctx, "fail", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.HasDiff() {
+		t.Error("HasDiff() = true, want false")
+	}
+	if _, err := r.Diff(ctx); err == nil {
+		t.Error("Diff() should fail without diff in handshake")
+	}
+	r.Post(ctx, newComment("tool", "a.go", "msg"))
+	err = r.Flush(ctx)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3: failed to post comments") {
+		t.Errorf("Flush() error = %v, want error with exit status and stderr", err)
+	}
+}
+
+func TestNewReporter_invalid(t *testing.T) {
+	setupPlugin(t, "oldversion", `echo '{"protocol_version": 2}'`)
+	setupPlugin(t, "broken", `echo 'not json'`)
+	for _, name := range []string{"", "../x", "notfound", "oldversion", "broken"} {
+		if _, err := NewReporter(context.Background(), name, nil, nil, nil); err == nil {
+			t.Errorf("NewReporter(%q) should fail", name)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
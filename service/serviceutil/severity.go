@@ -0,0 +1,168 @@
+package serviceutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// Severity returns the diagnostic severity or the severity from the report
+// level (info, warning, or error) if the diagnostic doesn't have it.
+func Severity(d *rdf.Diagnostic, level string) rdf.Severity {
+	if sv := d.GetSeverity(); sv != rdf.Severity_UNKNOWN_SEVERITY {
+		return sv
+	}
+	switch strings.ToLower(level) {
+	case "info":
+		return rdf.Severity_INFO
+	case "warning":
+		return rdf.Severity_WARNING
+	}
+	return rdf.Severity_ERROR
+}
+
+// SeverityName returns the lower case name of the severity, or "unknown" for
+// results without severity.
+func SeverityName(s rdf.Severity) string {
+	if s == rdf.Severity_UNKNOWN_SEVERITY {
+		return "unknown"
+	}
+	return strings.ToLower(s.String())
+}
+
+// SeverityCounts holds the number of results per severity.
+type SeverityCounts map[rdf.Severity]int
+
+// Total returns the number of all the results.
+func (c SeverityCounts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// AtOrAbove returns true if there are results at or above the threshold.
+func (c SeverityCounts) AtOrAbove(threshold rdf.Severity) bool {
+	for sv, n := range c {
+		if n > 0 && sv <= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the counts from the most severe one (e.g. "1 error, 2
+// warning"). It's empty if there are no results.
+func (c SeverityCounts) String() string {
+	var parts []string
+	for _, sv := range []rdf.Severity{rdf.Severity_ERROR, rdf.Severity_WARNING, rdf.Severity_INFO} {
+		if n := c[sv]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, SeverityName(sv)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Description returns a description of the results for commit statuses.
+func (c SeverityCounts) Description() string {
+	total := c.Total()
+	if total == 0 {
+		return "No findings"
+	}
+	return fmt.Sprintf("Found %d finding(s): %s", total, c)
+}
+
+// ToolSeverityCounts holds SeverityCounts per tool. Severity of diagnostics
+// without severity is the report level. It's not safe for concurrent use.
+type ToolSeverityCounts struct {
+	level  string
+	counts map[string]SeverityCounts
+}
+
+// NewToolSeverityCounts returns a new ToolSeverityCounts which has empty counts
+// for the given tools, so that tools without results are included as well.
+func NewToolSeverityCounts(tools []string, level string) *ToolSeverityCounts {
+	t := &ToolSeverityCounts{level: level, counts: make(map[string]SeverityCounts, len(tools))}
+	for _, tool := range tools {
+		t.counts[tool] = make(SeverityCounts)
+	}
+	return t
+}
+
+// Add counts the diagnostic of the tool.
+func (t *ToolSeverityCounts) Add(tool string, d *rdf.Diagnostic) {
+	if t.counts[tool] == nil {
+		t.counts[tool] = make(SeverityCounts)
+	}
+	t.counts[tool][Severity(d, t.level)]++
+}
+
+// Tools returns sorted names of the tools.
+func (t *ToolSeverityCounts) Tools() []string {
+	tools := make([]string, 0, len(t.counts))
+	for tool := range t.counts {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// Get returns the counts of the tool.
+func (t *ToolSeverityCounts) Get(tool string) SeverityCounts {
+	return t.counts[tool]
+}
+
+// Total returns the number of all the results of all the tools.
+func (t *ToolSeverityCounts) Total() int {
+	total := 0
+	for _, c := range t.counts {
+		total += c.Total()
+	}
+	return total
+}
+
+// CommitStatusName returns the name of commit status of the tool.
+func CommitStatusName(tool string) string {
+	if tool == "" {
+		return "reviewdog"
+	}
+	return "reviewdog/" + tool
+}
+
+// SeveritySummary is counts of results per severity for reports.
+type SeveritySummary struct {
+	Total   int `json:"total"`
+	Error   int `json:"error"`
+	Warning int `json:"warning"`
+	Info    int `json:"info"`
+	// Unknown is the count of results without severity.
+	Unknown int `json:"unknown"`
+}
+
+// Add counts a result of the severity.
+func (s *SeveritySummary) Add(severity rdf.Severity) {
+	s.Total++
+	switch severity {
+	case rdf.Severity_ERROR:
+		s.Error++
+	case rdf.Severity_WARNING:
+		s.Warning++
+	case rdf.Severity_INFO:
+		s.Info++
+	default:
+		s.Unknown++
+	}
+}
+
+// TruncateDescription truncates desc to max characters with "..." on a rune
+// boundary so that it stays valid UTF-8.
+func TruncateDescription(desc string, max int) string {
+	r := []rune(desc)
+	if len(r) <= max {
+		return desc
+	}
+	return string(r[:max-3]) + "..."
+}
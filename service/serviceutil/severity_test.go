@@ -0,0 +1,85 @@
+package serviceutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		severity rdf.Severity
+		level    string
+		want     rdf.Severity
+	}{
+		{severity: rdf.Severity_INFO, level: "error", want: rdf.Severity_INFO},
+		{level: "info", want: rdf.Severity_INFO},
+		{level: "Warning", want: rdf.Severity_WARNING},
+		{level: "error", want: rdf.Severity_ERROR},
+		{level: "", want: rdf.Severity_ERROR},
+	}
+	for _, tt := range tests {
+		if got := Severity(&rdf.Diagnostic{Severity: tt.severity}, tt.level); got != tt.want {
+			t.Errorf("Severity(%v, %q) = %v, want %v", tt.severity, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityCounts(t *testing.T) {
+	counts := SeverityCounts{}
+	if got, want := counts.Description(), "No findings"; got != want {
+		t.Errorf("Description() = %q, want %q", got, want)
+	}
+	counts[rdf.Severity_INFO] = 1
+	counts[rdf.Severity_WARNING] = 2
+	if got, want := counts.Description(), "Found 3 finding(s): 2 warning, 1 info"; got != want {
+		t.Errorf("Description() = %q, want %q", got, want)
+	}
+	if counts.AtOrAbove(rdf.Severity_ERROR) {
+		t.Error("AtOrAbove(ERROR) = true without errors")
+	}
+	if !counts.AtOrAbove(rdf.Severity_WARNING) {
+		t.Error("AtOrAbove(WARNING) = false with warnings")
+	}
+}
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "short", want: "short"},
+		{in: "0123456789", want: "0123456789"},
+		{in: "01234567890", want: "0123456..."},
+		{in: "あいうえおかきくけこさ", want: "あいうえおかき..."},
+	}
+	for _, tt := range tests {
+		got := TruncateDescription(tt.in, 10)
+		if got != tt.want {
+			t.Errorf("TruncateDescription(%q, 10) = %q, want %q", tt.in, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("TruncateDescription(%q, 10) = %q is invalid UTF-8", tt.in, got)
+		}
+	}
+}
+
+func TestToolSeverityCounts(t *testing.T) {
+	c := NewToolSeverityCounts([]string{"clean"}, "warning")
+	c.Add("tool", &rdf.Diagnostic{Severity: rdf.Severity_ERROR})
+	c.Add("tool", &rdf.Diagnostic{})
+	if got, want := strings.Join(c.Tools(), ","), "clean,tool"; got != want {
+		t.Errorf("Tools() = %q, want %q", got, want)
+	}
+	if got, want := c.Get("tool").String(), "1 error, 1 warning"; got != want {
+		t.Errorf("Get(tool) = %q, want %q", got, want)
+	}
+	if got := c.Get("clean").Description(); got != "No findings" {
+		t.Errorf("Get(clean).Description() = %q, want %q", got, "No findings")
+	}
+	if got := c.Total(); got != 2 {
+		t.Errorf("Total() = %d, want 2", got)
+	}
+}
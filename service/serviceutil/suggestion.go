@@ -0,0 +1,72 @@
+package serviceutil
+
+import (
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// SuggestionDiff is a suggestion as a diff of the source lines.
+type SuggestionDiff struct {
+	// StartLine is the line number of the first line of Old.
+	StartLine int
+	// Old is the source lines replaced by the suggestion. It's empty if the
+	// source lines are not available.
+	Old []string
+	// New is the suggested lines. It's empty if Old is deleted.
+	New []string
+}
+
+// BuildSuggestionDiff returns the suggestion as a diff of the source lines.
+// sourceLine returns the line of the line number, or false if it's not
+// available. New is the suggested text only if the source lines are not
+// available.
+func BuildSuggestionDiff(s *rdf.Suggestion, sourceLine func(lnum int) (string, bool)) *SuggestionDiff {
+	start, end := s.GetRange().GetStart(), s.GetRange().GetEnd()
+	startLine := int(start.GetLine())
+	endLine := int(end.GetLine())
+	if endLine < startLine {
+		endLine = startLine
+	}
+	sd := &SuggestionDiff{StartLine: startLine}
+	for lnum := startLine; lnum <= endLine; lnum++ {
+		line, ok := sourceLine(lnum)
+		if !ok {
+			sd.Old = nil
+			break
+		}
+		sd.Old = append(sd.Old, line)
+	}
+	if len(sd.Old) == 0 {
+		sd.New = strings.Split(s.GetText(), "\n")
+		return sd
+	}
+	if start.GetColumn() == 0 && end.GetColumn() == 0 {
+		// Line based suggestion.
+		if s.GetText() != "" {
+			sd.New = strings.Split(s.GetText(), "\n")
+		}
+		return sd
+	}
+	first, last := sd.Old[0], sd.Old[len(sd.Old)-1]
+	to := ColumnOffset(end.GetColumn(), last)
+	if end.GetLine() == 0 {
+		// Insertion at the start position.
+		to = ColumnOffset(start.GetColumn(), first)
+	}
+	sd.New = strings.Split(first[:ColumnOffset(start.GetColumn(), first)]+s.GetText()+last[to:], "\n")
+	return sd
+}
+
+// ColumnOffset returns the byte offset of the 1-based column in the line.
+// Columns out of the line are clamped to the start or end of the line.
+func ColumnOffset(col int32, line string) int {
+	i := int(col) - 1
+	if i < 0 {
+		return 0
+	}
+	if i > len(line) {
+		return len(line)
+	}
+	return i
+}
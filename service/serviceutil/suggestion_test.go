@@ -0,0 +1,72 @@
+package serviceutil
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestBuildSuggestionDiff(t *testing.T) {
+	src := map[int]string{1: "foo := bar", 2: "baz()"}
+	sourceLine := func(lnum int) (string, bool) {
+		l, ok := src[lnum]
+		return l, ok
+	}
+	tests := []struct {
+		name  string
+		start *rdf.Position
+		end   *rdf.Position
+		text  string
+		want  *SuggestionDiff
+	}{
+		{
+			name:  "columns",
+			start: &rdf.Position{Line: 1, Column: 1},
+			end:   &rdf.Position{Line: 1, Column: 4},
+			text:  "qux",
+			want:  &SuggestionDiff{StartLine: 1, Old: []string{"foo := bar"}, New: []string{"qux := bar"}},
+		},
+		{
+			name:  "multi lines with columns out of the lines",
+			start: &rdf.Position{Line: 1, Column: 8},
+			end:   &rdf.Position{Line: 2, Column: 100},
+			text:  "q\nx",
+			want:  &SuggestionDiff{StartLine: 1, Old: []string{"foo := bar", "baz()"}, New: []string{"foo := q", "x"}},
+		},
+		{
+			name:  "insertion",
+			start: &rdf.Position{Line: 2, Column: 4},
+			text:  "_",
+			want:  &SuggestionDiff{StartLine: 2, Old: []string{"baz()"}, New: []string{"baz_()"}},
+		},
+		{
+			name:  "line based",
+			start: &rdf.Position{Line: 1},
+			end:   &rdf.Position{Line: 2},
+			text:  "x",
+			want:  &SuggestionDiff{StartLine: 1, Old: []string{"foo := bar", "baz()"}, New: []string{"x"}},
+		},
+		{
+			name:  "deletion",
+			start: &rdf.Position{Line: 2},
+			want:  &SuggestionDiff{StartLine: 2, Old: []string{"baz()"}},
+		},
+		{
+			name:  "source lines are not available",
+			start: &rdf.Position{Line: 3, Column: 1},
+			end:   &rdf.Position{Line: 3, Column: 2},
+			text:  "x",
+			want:  &SuggestionDiff{StartLine: 3, New: []string{"x"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &rdf.Suggestion{Range: &rdf.Range{Start: tt.start, End: tt.end}, Text: tt.text}
+			if diff := cmp.Diff(tt.want, BuildSuggestionDiff(s, sourceLine)); diff != "" {
+				t.Errorf("BuildSuggestionDiff() has diff:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Package webhook provides a comment service which posts results to a
+// generic webhook as JSON.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.BulkCommentService = &Webhook{}
+
+// SignatureHeader is the header of HMAC-SHA256 signature of request body in
+// "sha256=<hex digest>" format. It's set only if the secret is given.
+const SignatureHeader = "X-Reviewdog-Signature-256"
+
+// DefaultRetries is the default number of retries.
+const DefaultRetries = 3
+
+const defaultBaseDelay = time.Second
+
+// Option is an option of Webhook.
+type Option struct {
+	// Secret signs request body with HMAC-SHA256 if it's not empty.
+	Secret string
+	// Retries is the number of retries on network errors, 429 and 5xx
+	// responses.
+	Retries int
+	// Template renders request body from Payload if it's not nil. Otherwise
+	// Payload is encoded as JSON.
+	Template *template.Template
+	// ContentType of request body. Defaults to "application/json".
+	ContentType string
+}
+
+// Payload is the data posted to webhook.
+type Payload struct {
+	Build   *Build  `json:"build"`
+	Tools   []*Tool `json:"tools"`
+	Summary Summary `json:"summary"`
+}
+
+// Build is build information of the results.
+type Build struct {
+	Owner       string `json:"owner,omitempty"`
+	Repo        string `json:"repo,omitempty"`
+	SHA         string `json:"sha,omitempty"`
+	PullRequest int    `json:"pull_request,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+}
+
+// Tool is results of a tool (runner).
+type Tool struct {
+	Name        string        `json:"name"`
+	Summary     Summary       `json:"summary"`
+	Diagnostics []*Diagnostic `json:"diagnostics"`
+}
+
+// Summary is counts of results per severity.
+type Summary = serviceutil.SeveritySummary
+
+// Diagnostic is a filtered result. Diagnostic is encoded in rdjson format.
+type Diagnostic struct {
+	Diagnostic    *rdf.Diagnostic
+	InDiffFile    bool
+	InDiffContext bool
+}
+
+// MarshalJSON encodes the diagnostic in rdjson format with filter results.
+func (d *Diagnostic) MarshalJSON() ([]byte, error) {
+	b, err := protojson.Marshal(d.Diagnostic)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		Diagnostic    json.RawMessage `json:"diagnostic"`
+		InDiffFile    bool            `json:"in_diff_file"`
+		InDiffContext bool            `json:"in_diff_context"`
+	}{b, d.InDiffFile, d.InDiffContext})
+}
+
+// Webhook posts results to the URL as a Payload. It posts once all the
+// runners are flushed so that a payload has results of all the tools, or on
+// every Flush if runners are not given.
+type Webhook struct {
+	cli   *http.Client
+	url   string
+	build *Build
+	opt   Option
+
+	mu       sync.Mutex
+	comments *commentutil.ToolComments
+
+	baseDelay time.Duration
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// NewWebhook returns a new Webhook which posts results to the given URL. build
+// can be nil if build information is not available.
+func NewWebhook(cli *http.Client, url string, build *cienv.BuildInfo, runners []string, opt Option) *Webhook {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	if opt.ContentType == "" {
+		opt.ContentType = "application/json"
+	}
+	w := &Webhook{
+		cli:       cli,
+		url:       url,
+		opt:       opt,
+		comments:  commentutil.NewToolComments(runners),
+		baseDelay: defaultBaseDelay,
+		sleep:     sleepCtx,
+	}
+	if build != nil {
+		w.build = &Build{
+			Owner:       build.Owner,
+			Repo:        build.Repo,
+			SHA:         build.SHA,
+			PullRequest: build.PullRequest,
+			Branch:      build.Branch,
+		}
+	}
+	return w
+}
+
+// Post accepts a comment and holds it. Flush method actually posts results.
+func (w *Webhook) Post(_ context.Context, c *reviewdog.Comment) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.comments.Add(c)
+	return nil
+}
+
+// Flush posts the held results once all the runners are flushed.
+func (w *Webhook) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.comments.Flush() {
+		return nil
+	}
+
+	body, err := w.buildBody(w.buildPayload())
+	if err != nil {
+		return err
+	}
+	w.comments.Reset()
+	return w.send(ctx, body)
+}
+
+// buildPayload returns a payload of the held results. Runners without results
+// are included as well.
+func (w *Webhook) buildPayload() *Payload {
+	tools := w.comments.Tools()
+	p := &Payload{Build: w.build, Tools: make([]*Tool, 0, len(tools))}
+	for _, name := range tools {
+		comments := w.comments.Comments(name)
+		tool := &Tool{Name: name, Diagnostics: make([]*Diagnostic, 0, len(comments))}
+		for _, c := range comments {
+			tool.Diagnostics = append(tool.Diagnostics, &Diagnostic{
+				Diagnostic:    c.Result.Diagnostic,
+				InDiffFile:    c.Result.InDiffFile,
+				InDiffContext: c.Result.InDiffContext,
+			})
+			tool.Summary.Add(c.Result.Diagnostic.GetSeverity())
+			p.Summary.Add(c.Result.Diagnostic.GetSeverity())
+		}
+		p.Tools = append(p.Tools, tool)
+	}
+	return p
+}
+
+func (w *Webhook) buildBody(p *Payload) ([]byte, error) {
+	if w.opt.Template == nil {
+		return json.Marshal(p)
+	}
+	var buf bytes.Buffer
+	if err := w.opt.Template.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("failed to execute webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// send posts the body and retries on network errors, 429 and 5xx responses
+// with exponential backoff.
+func (w *Webhook) send(ctx context.Context, body []byte) error {
+	var err error
+	for i := 0; i <= w.opt.Retries; i++ {
+		if i > 0 {
+			log.Printf("reviewdog: retrying webhook (%d/%d): %v", i, w.opt.Retries, err)
+			if serr := w.sleep(ctx, w.baseDelay<<(i-1)); serr != nil {
+				return serr
+			}
+		}
+		var retry bool
+		retry, err = w.sendOnce(ctx, body)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (w *Webhook) sendOnce(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", w.opt.ContentType)
+	req.Header.Set("User-Agent", "reviewdog")
+	if w.opt.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.opt.Secret, body))
+	}
+
+	resp, err := w.cli.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned unexpected response code %d: %s", resp.StatusCode, b)
+}
+
+// Sign returns the signature of body for SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseTemplate parses a template of request body. In addition to the
+// builtin functions, "json" function encodes a value as JSON.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,201 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func newComment(tool, path string, severity rdf.Severity, msg string) *reviewdog.Comment {
+	return &reviewdog.Comment{
+		ToolName: tool,
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  path,
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Severity: severity,
+				Message:  msg,
+			},
+			InDiffFile:    true,
+			InDiffContext: true,
+		},
+	}
+}
+
+func TestWebhook_Flush(t *testing.T) {
+	var requests int
+	var got map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.Header.Get(SignatureHeader), Sign("secret", body); got != want {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer ts.Close()
+
+	build := &cienv.BuildInfo{Owner: "o", Repo: "r", SHA: "sha", PullRequest: 14, Branch: "b"}
+	w := NewWebhook(nil, ts.URL, build, []string{"tool1", "tool2", "tool3"}, Option{Secret: "secret"})
+	ctx := context.Background()
+	w.Post(ctx, newComment("tool1", "a.go", rdf.Severity_ERROR, "error"))
+	w.Post(ctx, newComment("tool1", "b.go", rdf.Severity_UNKNOWN_SEVERITY, "unknown"))
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	w.Post(ctx, newComment("tool2", "a.go", rdf.Severity_WARNING, "warning"))
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 {
+		t.Fatalf("posted %d times before all the runners are flushed", requests)
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatalf("posted %d times, want once", requests)
+	}
+
+	var want map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+  "build": {"owner": "o", "repo": "r", "sha": "sha", "pull_request": 14, "branch": "b"},
+  "tools": [
+    {
+      "name": "tool1",
+      "summary": {"total": 2, "error": 1, "warning": 0, "info": 0, "unknown": 1},
+      "diagnostics": [
+        {
+          "diagnostic": {"message": "error", "location": {"path": "a.go", "range": {"start": {"line": 14}}}, "severity": "ERROR"},
+          "in_diff_file": true,
+          "in_diff_context": true
+        },
+        {
+          "diagnostic": {"message": "unknown", "location": {"path": "b.go", "range": {"start": {"line": 14}}}},
+          "in_diff_file": true,
+          "in_diff_context": true
+        }
+      ]
+    },
+    {
+      "name": "tool2",
+      "summary": {"total": 1, "error": 0, "warning": 1, "info": 0, "unknown": 0},
+      "diagnostics": [
+        {
+          "diagnostic": {"message": "warning", "location": {"path": "a.go", "range": {"start": {"line": 14}}}, "severity": "WARNING"},
+          "in_diff_file": true,
+          "in_diff_context": true
+        }
+      ]
+    },
+    {
+      "name": "tool3",
+      "summary": {"total": 0, "error": 0, "warning": 0, "info": 0, "unknown": 0},
+      "diagnostics": []
+    }
+  ],
+  "summary": {"total": 3, "error": 1, "warning": 1, "info": 0, "unknown": 1}
+}`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("payload diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWebhook_Flush_template(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		got = string(body)
+		if r.Header.Get(SignatureHeader) != "" {
+			t.Error("signature header is set without secret")
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "text/plain" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+	}))
+	defer ts.Close()
+
+	tmpl, err := ParseTemplate(`{{.Build.Repo}}#{{.Build.PullRequest}}: {{.Summary.Total}}` +
+		`{{range .Tools}} {{.Name}}={{json .Summary.Error}}{{range .Diagnostics}} {{.Diagnostic.Location.Path}}{{end}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWebhook(nil, ts.URL, &cienv.BuildInfo{Repo: "r", PullRequest: 14}, nil,
+		Option{Template: tmpl, ContentType: "text/plain"})
+	ctx := context.Background()
+	w.Post(ctx, newComment("tool1", "a.go", rdf.Severity_ERROR, "error"))
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := "r#14: 1 tool1=1 a.go"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestWebhook_Flush_retry(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []int
+		retries  int
+		wantErr  bool
+		wantReqs int
+	}{
+		{name: "success after retries", codes: []int{500, 429, 200}, retries: 3, wantReqs: 3},
+		{name: "give up", codes: []int{503, 503, 503}, retries: 2, wantErr: true, wantReqs: 3},
+		{name: "no retry on client error", codes: []int{400, 200}, retries: 3, wantErr: true, wantReqs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.codes[reqs])
+				reqs++
+			}))
+			defer ts.Close()
+
+			w := NewWebhook(nil, ts.URL, nil, nil, Option{Retries: tt.retries})
+			var delays []time.Duration
+			w.sleep = func(_ context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+			ctx := context.Background()
+			w.Post(ctx, newComment("tool", "a.go", rdf.Severity_ERROR, "error"))
+			err := w.Flush(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Flush() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if reqs != tt.wantReqs {
+				t.Errorf("sent %d requests, want %d", reqs, tt.wantReqs)
+			}
+			for i, d := range delays {
+				if want := defaultBaseDelay << i; d != want {
+					t.Errorf("delay #%d = %v, want %v", i, d, want)
+				}
+			}
+		})
+	}
+}